@@ -1,25 +1,42 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"log/slog"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "GoTasker/docs"
 	"GoTasker/internal/config"
 	"GoTasker/internal/delivery/http"
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	"GoTasker/internal/events"
+	"GoTasker/internal/i18n"
 	"GoTasker/internal/logger"
+	"GoTasker/internal/metrics"
+	"GoTasker/internal/scheduler"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	// Handlers
+	adminHandler "GoTasker/internal/handler/admin"
 	analyticsHandler "GoTasker/internal/handler/analytics"
 	authHandler "GoTasker/internal/handler/auth"
+	healthHandler "GoTasker/internal/handler/health"
 	tasksHandler "GoTasker/internal/handler/tasks"
 
 	// Repositories
+	healthRepo "GoTasker/internal/repository/postgres/health"
 	tasksRepo "GoTasker/internal/repository/postgres/tasks"
 	usersRepo "GoTasker/internal/repository/postgres/users"
 	"GoTasker/internal/repository/redis"
@@ -27,9 +44,11 @@ import (
 	// UseCases
 	analyticsUC "GoTasker/internal/useCase/analytics"
 	authUC "GoTasker/internal/useCase/auth"
+	healthUC "GoTasker/internal/useCase/health"
 	tasksUC "GoTasker/internal/useCase/tasks"
 
 	"GoTasker/internal/useCase"
+	"GoTasker/migrations"
 )
 
 // @title GoTasker API
@@ -52,6 +71,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Подкоманда migrate: управление схемой без запуска сервера
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, os.Args[2:]))
+	}
+
 	slog.Info("Запуск приложения", "environment", cfg.Env)
 
 	// Установка соединения с базой данных Psql
@@ -60,7 +84,6 @@ func main() {
 		slog.Error(op, "Ошибка подключения к базе данных:", err)
 		os.Exit(1)
 	}
-	defer db.Close()
 
 	// Проверка соединения с базой данных
 	if err = db.Ping(); err != nil {
@@ -68,32 +91,204 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Миграции при запуске
+	if cfg.DB.AutoMigrate {
+		if err = autoMigrate(cfg); err != nil {
+			slog.Error(op, "Ошибка миграции базы данных:", err)
+			os.Exit(1)
+		}
+	}
+
 	// Репозитории
 	taskRepo := tasksRepo.NewTaskPostgresRepo(db)
 	userRepo := usersRepo.NewUserPostgresRepo(db)
+	dbHealthRepo := healthRepo.NewHealthPostgresRepo(db)
 	analyticsRedis := redis.NewAnalyticsRedisRepo(cfg)
+	tokenRedis := redis.NewTokenRedisRepo(cfg)
+	jobRedis := redis.NewJobRedisRepo(cfg)
+
+	// Статусы задач и переходы между ними
+	transitions, err := domain.ParseStatusTransitions(cfg.Workflow.Transitions)
+	if err != nil {
+		slog.Error(op, "Некорректный TASK_STATUS_TRANSITIONS:", err)
+		os.Exit(1)
+	}
+	workflow, err := domain.NewWorkflow(domain.Status(cfg.Workflow.InitialStatus), transitions)
+	if err != nil {
+		slog.Error(op, "Некорректная настройка статусов задач:", err)
+		os.Exit(1)
+	}
+
+	// Версия схемы, которую ожидает этот бинарник; /readyz сверяет её с базой
+	schemaVersion, err := migrations.LatestVersion()
+	if err != nil {
+		slog.Error(op, "Ошибка чтения встроенных миграций:", err)
+		os.Exit(1)
+	}
 
 	// UseCases
-	taskUC := tasksUC.NewTaskUseCase(taskRepo)
-	authUseCase := authUC.NewAuthUseCase(userRepo, cfg)
+	taskEvents := events.NewBus() // Изменения задач: по ним сбрасывается кэш аналитики
+	taskUC := tasksUC.NewTaskUseCase(taskRepo, workflow, taskEvents)
+	authUseCase := authUC.NewAuthUseCase(userRepo, tokenRedis, cfg)
 	analyticUC := analyticsUC.NewAnalyticsUseCase(taskRepo, analyticsRedis)
-	backgroundJob := useCase.NewBackgroundJob(taskRepo)
+	taskEvents.Subscribe(analyticUC.HandleTaskChange)
+	healthUseCase := healthUC.NewHealthUseCase(dbHealthRepo, tokenRedis, schemaVersion)
+	backgroundJob := useCase.NewBackgroundJob(taskUC, taskRepo,
+		time.Duration(cfg.Server.TaskCleanupDays)*24*time.Hour,
+		time.Duration(cfg.Server.TrashRetentionDays)*24*time.Hour,
+	)
+
+	// Планировщик фоновых задач; каждый запуск выполняет одна реплика
+	jobScheduler, err := newScheduler(cfg, jobRedis, backgroundJob)
+	if err != nil {
+		slog.Error(op, "Некорректная настройка фоновых задач:", err)
+		os.Exit(1)
+	}
 
 	// Handlers
 	taskHand := tasksHandler.NewTaskHandler(taskUC)
 	authHand := authHandler.NewUserAuthHandler(authUseCase)
 	analyticHand := analyticsHandler.NewAnalyticsHandler(analyticUC)
+	adminHand := adminHandler.NewAdminHandler(jobScheduler)
+	healthHand := healthHandler.NewHealthHandler(healthUseCase)
+
+	// Middlewares
+	authMiddleware := middleware.NewAuthMiddleware(cfg.Server.JWTSecret, tokenRedis)
+	localeMiddleware := middleware.NewLocaleMiddleware(i18n.Lang(cfg.Server.DefaultLanguage))
+	adminMiddleware := middleware.NewAdminMiddleware(cfg.Server.AdminUserIDs)
+
+	// Количество задач по статусу и приоритету считается при каждом сборе метрик
+	prometheus.MustRegister(metrics.NewTaskCollector(taskRepo))
 
 	// Маршруты
 	r := gin.Default()
-	http.SetupRoutes(r, taskHand, analyticHand, authHand)
+	http.SetupRoutes(r, taskHand, analyticHand, authHand, adminHand, healthHand, authMiddleware, adminMiddleware, localeMiddleware)
+
+	// Остановка по SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Запуск фоновых задач
-	go backgroundJob.StartTaskCleanup(taskRepo, cfg.Server.TaskCleanupDays)
+	// Запуск фоновых задач. Контекст отдельный: задачи останавливаются после завершения HTTP-запросов
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		jobScheduler.Run(jobsCtx)
+	}()
 
-	// Старт сервера
-	slog.Warn(fmt.Sprintf("Сервер запущен и прослушивает порт %s\n", cfg.Server.Port))
-	if err = r.Run(cfg.Server.Port); err != nil {
-		log.Fatalf("ошибка запуска сервера: %v", err)
+	// Старт сервера. Контекст запросов не связан с сигналом, чтобы начатые запросы, например импорт, успели завершиться
+	srv := &nethttp.Server{
+		Addr:              cfg.Server.Port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+	// Метрики отдаются на отдельном адресе, недоступном снаружи: в них маршруты и коды ответов всех клиентов
+	metricsSrv := &nethttp.Server{
+		Addr:              cfg.Server.MetricsAddr,
+		Handler:           promhttp.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Warn(fmt.Sprintf("Сервер запущен и прослушивает порт %s\n", cfg.Server.Port))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	go func() {
+		slog.Info("Метрики Prometheus доступны", slog.String("addr", cfg.Server.MetricsAddr))
+		if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		slog.Info("Получен сигнал остановки, завершение работы", "timeout", cfg.Server.ShutdownTimeout)
+	case err = <-serverErr:
+		slog.Error(op, "Ошибка запуска сервера:", err)
+		exitCode = 1
+	}
+	// Повторный сигнал завершит процесс сразу, не дожидаясь остановки
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// 1. Перестаём принимать соединения и ждём завершения начатых запросов
+	if err = srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error(op, "Не все запросы завершились до таймаута остановки:", err)
+		exitCode = 1
+	}
+	if err = metricsSrv.Shutdown(shutdownCtx); err != nil {
+		slog.Error(op, "Сервер метрик не остановился до таймаута остановки:", err)
+		exitCode = 1
+	}
+
+	// 2. Останавливаем фоновые задачи: выполняемая попытка отменяется, её транзакция откатывается
+	stopJobs()
+	select {
+	case <-jobsDone:
+	case <-shutdownCtx.Done():
+		slog.Error(op, "Фоновые задачи не остановились до таймаута остановки:", shutdownCtx.Err())
+		exitCode = 1
+	}
+
+	// 3. Закрываем соединения, когда их больше никто не использует
+	closers := []struct {
+		name   string
+		closer io.Closer
+	}{
+		{"postgres", db},
+		{"redis analytics", analyticsRedis},
+		{"redis tokens", tokenRedis},
+		{"redis jobs", jobRedis},
+	}
+	for _, c := range closers {
+		if err = c.closer.Close(); err != nil {
+			slog.Error(op, "Ошибка закрытия соединения", slog.String("name", c.name), slog.String("err", err.Error()))
+			exitCode = 1
+		}
+	}
+
+	slog.Info("Приложение остановлено")
+	os.Exit(exitCode)
+}
+
+// newScheduler собирает фоновые задачи с расписаниями из конфигурации
+func newScheduler(cfg *config.Config, store scheduler.Store, backgroundJob *useCase.BackgroundJob) (*scheduler.Scheduler, error) {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+
+	jobs := []struct {
+		name string
+		spec string
+		run  scheduler.RunFunc
+	}{
+		{"task_cleanup", cfg.Jobs.TaskCleanupSchedule, backgroundJob.CleanupExpiredTasks},
+		{"trash_purge", cfg.Jobs.TrashPurgeSchedule, backgroundJob.PurgeTrash},
+		{"daily_snapshot", cfg.Jobs.DailySnapshotSchedule, backgroundJob.SnapshotDailyCounts},
+	}
+
+	scheduled := make([]scheduler.Job, 0, len(jobs))
+	for _, job := range jobs {
+		schedule, err := scheduler.ParseSchedule(job.spec)
+		if err != nil {
+			return nil, err
+		}
+		scheduled = append(scheduled, scheduler.Job{
+			Name:     job.name,
+			Schedule: schedule,
+			Timeout:  cfg.Jobs.Timeout,
+			Retries:  cfg.Jobs.Retries,
+			Backoff:  cfg.Jobs.Backoff,
+			Run:      job.run,
+		})
+	}
+
+	return scheduler.New(store, instance, scheduled...)
 }
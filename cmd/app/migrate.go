@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"GoTasker/internal/config"
+	"GoTasker/internal/migrator"
+)
+
+// runMigrate выполняет подкоманду migrate: go run ./cmd/app migrate up
+func runMigrate(cfg *config.Config, args []string) int {
+	cmd, err := migrator.ParseCommand(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n%s\n", err, migrator.Usage)
+		return 2
+	}
+
+	m, err := migrator.New(cfg.DB.GetConnectionString())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer m.Close()
+
+	if err = m.Execute(cmd, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "ошибка выполнения migrate %s: %v\n", cmd.Name, err)
+		return 1
+	}
+
+	return 0
+}
+
+// autoMigrate применяет новые миграции при запуске. Реплики, запущенные одновременно, ждут advisory lock
+// и применяют миграции по очереди: первая обновляет схему, остальным остаётся нечего применять
+func autoMigrate(cfg *config.Config) error {
+	const op = "cmd.app.autoMigrate"
+
+	m, err := migrator.New(cfg.DB.GetConnectionString())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err = m.Up(); err != nil {
+		return fmt.Errorf("ошибка применения миграций: %w", err)
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	slog.Info(op, "версия схемы", status.Version)
+
+	return nil
+}
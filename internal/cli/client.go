@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"GoTasker/internal/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrNotLoggedIn нет токенов или сессию не удалось продлить
+var ErrNotLoggedIn = errors.New("требуется вход: выполните gotasker login")
+
+// APIError ответ API с ошибкой в формате application/problem+json
+type APIError struct {
+	Status  int          `json:"status"`
+	Title   string       `json:"title"`
+	Detail  string       `json:"detail"`
+	Code    string       `json:"code"`
+	Field   string       `json:"field"`
+	Details []string     `json:"details"`
+	Current *domain.Task `json:"current"` // Актуальное состояние задачи при конфликте версий (412)
+}
+
+func (e *APIError) Error() string {
+	msg := e.Title
+	if e.Detail != "" {
+		msg = e.Detail
+	}
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if len(e.Details) > 0 {
+		msg += "\n  " + strings.Join(e.Details, "\n  ")
+	}
+	return fmt.Sprintf("%s (%d %s)", msg, e.Status, e.Code)
+}
+
+// ImportResult результат импорта задач
+type ImportResult struct {
+	Message  string   `json:"message"`
+	Inserted int      `json:"inserted_tasks"`
+	Skipped  []string `json:"skipped_tasks"` // Причины, по которым задачи не импортированы
+
+}
+
+// Client клиент GoTasker API. Токены берутся из Config; истёкший access токен обновляется
+// по refresh токену, новая пара сразу сохраняется, потому что старый refresh токен больше не действует
+type Client struct {
+	http *http.Client
+	cfg  *Config
+}
+
+func NewClient(cfg *Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		http: httpClient,
+		cfg:  cfg,
+	}
+}
+
+type tokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Login входит по email и паролю и сохраняет токены
+func (c *Client) Login(ctx context.Context, email, password string) error {
+	body, err := json.Marshal(map[string]string{"email": email, "password": password})
+	if err != nil {
+		return err
+	}
+
+	var tokens tokenPair
+	if err = c.send(ctx, request{method: http.MethodPost, path: "/auth/login", body: body, contentType: "application/json"}, "", &tokens); err != nil {
+		return err
+	}
+
+	return c.saveTokens(tokens)
+}
+
+// Logout завершает сессию на сервере и удаляет токены из настроек
+func (c *Client) Logout(ctx context.Context) error {
+	if c.cfg.AccessToken != "" {
+		err := c.do(ctx, request{method: http.MethodPost, path: "/auth/logout"}, nil)
+		if err != nil && !errors.Is(err, ErrNotLoggedIn) {
+			return err
+		}
+	}
+
+	return c.saveTokens(tokenPair{})
+}
+
+// ListTasks возвращает страницу задач; query — фильтры, сортировка и курсор в формате GET /tasks
+func (c *Client) ListTasks(ctx context.Context, query url.Values) (*domain.TaskPage, error) {
+	var page domain.TaskPage
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/tasks", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// CreateTask создаёт задачу из полей CreateTaskRequest
+func (c *Client) CreateTask(ctx context.Context, fields map[string]interface{}) (*domain.Task, error) {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var task domain.Task
+	if err = c.do(ctx, request{method: http.MethodPost, path: "/tasks", body: body, contentType: "application/json"}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask возвращает задачу по ID
+func (c *Client) GetTask(ctx context.Context, id int64) (*domain.Task, error) {
+	var task domain.Task
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/tasks/" + strconv.FormatInt(id, 10)}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// PatchTask меняет переданные поля задачи, если её версия на сервере всё ещё равна version.
+// Иначе сервер отвечает 412, и APIError.Current содержит актуальное состояние задачи
+func (c *Client) PatchTask(ctx context.Context, id, version int64, fields map[string]interface{}) (*domain.Task, error) {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var task domain.Task
+	req := request{
+		method:      http.MethodPatch,
+		path:        "/tasks/" + strconv.FormatInt(id, 10),
+		body:        body,
+		contentType: "application/merge-patch+json",
+		ifMatch:     strconv.Quote(strconv.FormatInt(version, 10)),
+	}
+	if err = c.do(ctx, req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask перемещает задачу в корзину, с permanent — удаляет безвозвратно
+func (c *Client) DeleteTask(ctx context.Context, id int64, permanent bool) error {
+	req := request{method: http.MethodDelete, path: "/tasks/" + strconv.FormatInt(id, 10)}
+	if permanent {
+		req.query = url.Values{"permanent": {"true"}}
+	}
+	return c.do(ctx, req, nil)
+}
+
+// ImportTasks загружает JSON-файл с задачами
+func (c *Client) ImportTasks(ctx context.Context, filename string, data []byte) (*ImportResult, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(data); err != nil {
+		return nil, err
+	}
+	if err = form.Close(); err != nil {
+		return nil, err
+	}
+
+	var result ImportResult
+	req := request{method: http.MethodPost, path: "/tasks/import", body: buf.Bytes(), contentType: form.FormDataContentType()}
+	if err = c.do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportTasks возвращает все задачи пользователя
+func (c *Client) ExportTasks(ctx context.Context) ([]*domain.Task, error) {
+	var tasks []*domain.Task
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/tasks/export"}, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// request описание запроса; тело хранится целиком, чтобы повторить запрос после обновления токенов
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	ifMatch     string
+}
+
+// do выполняет запрос с access токеном. При 401 один раз обновляет токены и повторяет запрос
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	if c.cfg.AccessToken == "" {
+		return ErrNotLoggedIn
+	}
+
+	err := c.send(ctx, req, c.cfg.AccessToken, out)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		return err
+	}
+
+	if err = c.refresh(ctx); err != nil {
+		return err
+	}
+
+	return c.send(ctx, req, c.cfg.AccessToken, out)
+}
+
+// refresh обменивает refresh токен на новую пару и сохраняет её
+func (c *Client) refresh(ctx context.Context) error {
+	if c.cfg.RefreshToken == "" {
+		return ErrNotLoggedIn
+	}
+
+	body, err := json.Marshal(map[string]string{"refresh_token": c.cfg.RefreshToken})
+	if err != nil {
+		return err
+	}
+
+	var tokens tokenPair
+	err = c.send(ctx, request{method: http.MethodPost, path: "/auth/refresh", body: body, contentType: "application/json"}, "", &tokens)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+		// Refresh токен истёк или отозван: продлить сессию нельзя, токены больше не нужны
+		_ = c.saveTokens(tokenPair{})
+		return ErrNotLoggedIn
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка обновления токенов: %w", err)
+	}
+
+	return c.saveTokens(tokens)
+}
+
+func (c *Client) saveTokens(tokens tokenPair) error {
+	c.cfg.AccessToken = tokens.AccessToken
+	c.cfg.RefreshToken = tokens.RefreshToken
+	return c.cfg.Save()
+}
+
+// send выполняет один HTTP-запрос и декодирует JSON-ответ в out
+func (c *Client) send(ctx context.Context, req request, accessToken string, out interface{}) error {
+	target := strings.TrimRight(c.cfg.Server, "/") + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if req.ifMatch != "" {
+		httpReq.Header.Set("If-Match", req.ifMatch)
+	}
+	if accessToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к %s: %w", c.cfg.Server, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{Status: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(apiErr)
+		apiErr.Status = resp.StatusCode
+		return apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("некорректный ответ сервера: %w", err)
+	}
+
+	return nil
+}
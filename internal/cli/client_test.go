@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeAPI эмулирует аутентификацию GoTasker API: действителен только последний выданный access токен,
+// refresh токен одноразовый
+type fakeAPI struct {
+	mu       sync.Mutex
+	access   string
+	refresh  string
+	issued   int
+	refreshs int
+	requests []*http.Request
+	bodies   [][]byte
+	handler  http.HandlerFunc // Ответ на запрос с действующим access токеном
+}
+
+func newFakeAPI(t *testing.T, handler http.HandlerFunc) (*fakeAPI, *httptest.Server) {
+	api := &fakeAPI{handler: handler}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return api, srv
+}
+
+func (a *fakeAPI) issue(w http.ResponseWriter) {
+	a.issued++
+	a.access = "access-" + strconv.Itoa(a.issued)
+	a.refresh = "refresh-" + strconv.Itoa(a.issued)
+	_ = json.NewEncoder(w).Encode(map[string]string{"access_token": a.access, "refresh_token": a.refresh})
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	a.requests = append(a.requests, r)
+	a.bodies = append(a.bodies, body)
+
+	switch r.URL.Path {
+	case "/auth/login":
+		var creds map[string]string
+		_ = json.Unmarshal(body, &creds)
+		if creds["password"] != "secret" {
+			problem(w, http.StatusUnauthorized, "invalid_credentials")
+			return
+		}
+		a.issue(w)
+	case "/auth/refresh":
+		a.refreshs++
+		var req map[string]string
+		_ = json.Unmarshal(body, &req)
+		if req["refresh_token"] == "" || req["refresh_token"] != a.refresh {
+			problem(w, http.StatusUnauthorized, "invalid_refresh_token")
+			return
+		}
+		a.issue(w)
+	default:
+		if r.Header.Get("Authorization") != "Bearer "+a.access || a.access == "" {
+			problem(w, http.StatusUnauthorized, "token_expired")
+			return
+		}
+		a.handler(w, r)
+	}
+}
+
+// expire делает текущий access токен недействительным, как по истечении срока
+func (a *fakeAPI) expire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.access = "expired-on-server"
+}
+
+func problem(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": status, "title": http.StatusText(status), "code": code})
+}
+
+func testConfig(t *testing.T, server string) *Config {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "gotasker", "config.json"))
+	require.NoError(t, err)
+	cfg.Server = server
+	return cfg
+}
+
+func TestClient_LoginAndRefresh(t *testing.T) {
+	api, srv := newFakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(domain.TaskPage{Items: []*domain.Task{{ID: 1, Title: "отчёт"}}, Total: 1})
+	})
+	cfg := testConfig(t, srv.URL)
+	client := NewClient(cfg, srv.Client())
+	ctx := context.Background()
+
+	_, err := client.ListTasks(ctx, nil)
+	assert.ErrorIs(t, err, ErrNotLoggedIn)
+
+	var apiErr *APIError
+	require.ErrorAs(t, client.Login(ctx, "user@example.com", "wrong"), &apiErr)
+	assert.Equal(t, "invalid_credentials", apiErr.Code)
+
+	require.NoError(t, client.Login(ctx, "user@example.com", "secret"))
+
+	saved, err := LoadConfig(cfg.path)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", saved.AccessToken)
+	assert.Equal(t, "refresh-1", saved.RefreshToken)
+
+	info, err := os.Stat(cfg.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "токены доступны только владельцу")
+
+	t.Run("истёкший access токен обновляется, запрос повторяется", func(t *testing.T) {
+		api.expire()
+
+		page, err := client.ListTasks(ctx, nil)
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, 1, api.refreshs)
+
+		saved, err := LoadConfig(cfg.path)
+		require.NoError(t, err)
+		assert.Equal(t, "access-2", saved.AccessToken, "новая пара сохраняется сразу: старый refresh токен больше не действует")
+		assert.Equal(t, "refresh-2", saved.RefreshToken)
+	})
+
+	t.Run("недействительный refresh токен требует нового входа", func(t *testing.T) {
+		api.expire()
+		cfg.RefreshToken = "revoked"
+
+		_, err := client.ListTasks(ctx, nil)
+		assert.ErrorIs(t, err, ErrNotLoggedIn)
+
+		saved, err := LoadConfig(cfg.path)
+		require.NoError(t, err)
+		assert.Empty(t, saved.AccessToken)
+		assert.Empty(t, saved.RefreshToken)
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	err := error(&APIError{Status: 400, Title: "Bad Request", Detail: "Неверный приоритет", Code: "validation_failed", Field: "priority"})
+	assert.Equal(t, "priority: Неверный приоритет (400 validation_failed)", err.Error())
+	assert.False(t, errors.Is(err, ErrNotLoggedIn))
+}
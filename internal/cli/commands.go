@@ -0,0 +1,502 @@
+package cli
+
+import (
+	"GoTasker/internal/domain"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPageSize наибольший размер страницы GET /tasks
+const maxPageSize = 100
+
+const usage = `gotasker — клиент GoTasker API
+
+использование: gotasker [-config файл] <команда> [флаги]
+
+команды:
+  login   -email E [-password P] [-server URL]  вход; пароль также берётся из GOTASKER_PASSWORD или stdin
+  logout                                         завершение сессии
+  list    [-status S1,S2] [-priority P1,P2] [-overdue] [-due-before D] [-due-after D]
+          [-title T] [-sort поле] [-limit N] [-all] [-format F]
+  add     -title T [-description D] [-priority P] [-status S] [-due D] [-format F]
+  edit    ID [-title T] [-description D] [-priority P] [-status S] [-due D]
+          [-version N] [-format F]
+  done    ID [-status S] [-version N] [-format F]
+          перевод в статус done
+  rm      ID [-permanent]                         перемещение в корзину или безвозвратное удаление
+  import  FILE                                    импорт задач из JSON-файла
+  export  [-format F] [-o FILE]                   экспорт всех задач, по умолчанию в JSON
+
+edit и done сверяют версию задачи: -version из ETag или, по умолчанию, прочитанную перед изменением
+даты: YYYY-MM-DD (полночь UTC) или RFC3339; форматы вывода: table (по умолчанию), json, csv
+настройки и токены: -config, GOTASKER_CONFIG или <каталог настроек пользователя>/gotasker/config.json`
+
+// errUsage неверные аргументы команды; подробности уже выведены
+var errUsage = errors.New("неверные аргументы")
+
+// env окружение команды
+type env struct {
+	client *Client
+	cfg    *Config
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run выполняет команду CLI и возвращает код завершения: 0 — успех, 1 — ошибка, 2 — неверные аргументы
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("gotasker", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { fmt.Fprintln(stderr, usage) }
+	configPath := global.String("config", "", "файл настроек")
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+
+	if *configPath == "" {
+		path, err := DefaultConfigPath()
+		if err != nil {
+			fmt.Fprintf(stderr, "ошибка: %v\n", err)
+			return 1
+		}
+		*configPath = path
+	}
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return 1
+	}
+
+	e := &env{client: NewClient(cfg, nil), cfg: cfg, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	commands := map[string]func(ctx context.Context, args []string) error{
+		"login":  e.login,
+		"logout": e.logout,
+		"list":   e.list,
+		"add":    e.add,
+		"edit":   e.edit,
+		"done":   e.done,
+		"rm":     e.rm,
+		"import": e.importTasks,
+		"export": e.export,
+	}
+
+	name := global.Arg(0)
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "неизвестная команда %q\n\n%s\n", name, usage)
+		return 2
+	}
+
+	if err = command(ctx, global.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "ошибка: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func (e *env) login(ctx context.Context, args []string) error {
+	fs := e.flagSet("login")
+	server := fs.String("server", "", "адрес API, по умолчанию сохранённый или "+DefaultServer)
+	email := fs.String("email", "", "email")
+	password := fs.String("password", "", "пароль")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *email == "" {
+		return e.usageError(fs, "не указан -email")
+	}
+
+	if *password == "" {
+		*password = os.Getenv("GOTASKER_PASSWORD")
+	}
+	if *password == "" {
+		fmt.Fprint(e.stderr, "Пароль: ")
+		line, err := bufio.NewReader(e.stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if *server != "" {
+		e.cfg.Server = *server
+	}
+
+	if err := e.client.Login(ctx, *email, *password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "Вход выполнен: %s\n", e.cfg.Server)
+	return nil
+}
+
+func (e *env) logout(ctx context.Context, args []string) error {
+	fs := e.flagSet("logout")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	if err := e.client.Logout(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.stdout, "Сессия завершена")
+	return nil
+}
+
+func (e *env) list(ctx context.Context, args []string) error {
+	fs := e.flagSet("list")
+	status := fs.String("status", "", "статусы через запятую")
+	priority := fs.String("priority", "", "приоритеты через запятую")
+	overdue := fs.Bool("overdue", false, "только просроченные невыполненные задачи")
+	dueBefore := fs.String("due-before", "", "срок раньше даты")
+	dueAfter := fs.String("due-after", "", "срок позже даты")
+	title := fs.String("title", "", "поиск по названию")
+	sort := fs.String("sort", "", "сортировка: due_date, priority, created_at, updated_at, title; '-' — по убыванию")
+	limit := fs.Int("limit", 20, "сколько задач вывести")
+	all := fs.Bool("all", false, "вывести все задачи")
+	format := formatFlag(fs, FormatTable)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *limit <= 0 && !*all {
+		return e.usageError(fs, "-limit должен быть положительным")
+	}
+
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"status": *status, "priority": *priority, "due_before": *dueBefore, "due_after": *dueAfter, "title": *title, "sort": *sort,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if *overdue {
+		query.Set("overdue", "true")
+	}
+
+	var tasks []*domain.Task
+	for {
+		pageSize := maxPageSize
+		if !*all {
+			pageSize = min(maxPageSize, *limit-len(tasks))
+		}
+		query.Set("limit", strconv.Itoa(pageSize))
+
+		page, err := e.client.ListTasks(ctx, query)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, page.Items...)
+
+		if page.NextCursor == "" || (!*all && len(tasks) >= *limit) {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+
+	return WriteTasks(e.stdout, *format, tasks)
+}
+
+func (e *env) add(ctx context.Context, args []string) error {
+	fs := e.flagSet("add")
+	fields := taskFlags(fs)
+	format := formatFlag(fs, FormatTable)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	values, err := fields.values(fs)
+	if err != nil {
+		return e.usageError(fs, err.Error())
+	}
+	if values["title"] == nil || values["title"] == "" {
+		return e.usageError(fs, "не указан -title")
+	}
+
+	task, err := e.client.CreateTask(ctx, values)
+	if err != nil {
+		return err
+	}
+
+	return WriteTask(e.stdout, *format, task)
+}
+
+func (e *env) edit(ctx context.Context, args []string) error {
+	fs := e.flagSet("edit")
+	fields := taskFlags(fs)
+	version := versionFlag(fs)
+	format := formatFlag(fs, FormatTable)
+	id, err := parseWithID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	values, err := fields.values(fs)
+	if err != nil {
+		return e.usageError(fs, err.Error())
+	}
+	if len(values) == 0 {
+		return e.usageError(fs, "не указано ни одного изменяемого поля")
+	}
+
+	task, err := e.patchTask(ctx, id, *version, values)
+	if err != nil {
+		return err
+	}
+
+	return WriteTask(e.stdout, *format, task)
+}
+
+func (e *env) done(ctx context.Context, args []string) error {
+	fs := e.flagSet("done")
+	status := fs.String("status", string(domain.StatusDone), "статус выполненной задачи, если в TASK_STATUS_TRANSITIONS он называется иначе")
+	version := versionFlag(fs)
+	format := formatFlag(fs, FormatTable)
+	id, err := parseWithID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	task, err := e.patchTask(ctx, id, *version, map[string]interface{}{"status": *status})
+	if err != nil {
+		return err
+	}
+
+	return WriteTask(e.stdout, *format, task)
+}
+
+// patchTask меняет задачу с проверкой версии. Без -version берётся версия, прочитанная с сервера
+// непосредственно перед изменением; если задачу успели изменить, изменение не применяется
+func (e *env) patchTask(ctx context.Context, id, version int64, values map[string]interface{}) (*domain.Task, error) {
+	if version == 0 {
+		current, err := e.client.GetTask(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		version = current.Version
+	}
+
+	task, err := e.client.PatchTask(ctx, id, version, values)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusPreconditionFailed && apiErr.Current != nil {
+		return nil, fmt.Errorf("задача %d изменена другим запросом: ожидалась версия %d, текущая %d; проверьте задачу и повторите команду",
+			id, version, apiErr.Current.Version)
+	}
+	return task, err
+}
+
+func (e *env) rm(ctx context.Context, args []string) error {
+	fs := e.flagSet("rm")
+	permanent := fs.Bool("permanent", false, "удалить безвозвратно, в том числе из корзины")
+	id, err := parseWithID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err = e.client.DeleteTask(ctx, id, *permanent); err != nil {
+		return err
+	}
+
+	if *permanent {
+		fmt.Fprintf(e.stdout, "Задача %d удалена безвозвратно\n", id)
+	} else {
+		fmt.Fprintf(e.stdout, "Задача %d перемещена в корзину\n", id)
+	}
+	return nil
+}
+
+func (e *env) importTasks(ctx context.Context, args []string) error {
+	fs := e.flagSet("import")
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	result, err := e.client.ImportTasks(ctx, filepath.Base(path), data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "Добавлено задач: %d, пропущено: %d\n", result.Inserted, len(result.Skipped))
+	for _, reason := range result.Skipped {
+		fmt.Fprintf(e.stdout, "  %s\n", reason)
+	}
+	return nil
+}
+
+func (e *env) export(ctx context.Context, args []string) error {
+	fs := e.flagSet("export")
+	format := formatFlag(fs, FormatJSON)
+	output := fs.String("o", "", "файл; по умолчанию stdout")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	tasks, err := e.client.ExportTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return WriteTasks(e.stdout, *format, tasks)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err = WriteTasks(file, *format, tasks); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stderr, "Экспортировано задач: %d в %s\n", len(tasks), *output)
+	return nil
+}
+
+func (e *env) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("gotasker "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+func (e *env) usageError(fs *flag.FlagSet, msg string) error {
+	fmt.Fprintf(e.stderr, "%s: %s\n", fs.Name(), msg)
+	fs.PrintDefaults()
+	return errUsage
+}
+
+// parse разбирает флаги и проверяет число позиционных аргументов
+func parse(fs *flag.FlagSet, args []string, positional int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != positional {
+		fmt.Fprintf(fs.Output(), "%s: ожидается аргументов: %d, передано: %d\n", fs.Name(), positional, fs.NArg())
+		return errUsage
+	}
+	return nil
+}
+
+// parseWithID разбирает ID задачи и флаги; ID можно указать как до, так и после флагов
+func parseWithID(fs *flag.FlagSet, args []string) (int64, error) {
+	var raw string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		raw, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return 0, errUsage
+	}
+
+	rest := fs.Args()
+	if raw == "" && len(rest) > 0 {
+		raw, rest = rest[0], rest[1:]
+	}
+	if raw == "" || len(rest) > 0 {
+		fmt.Fprintf(fs.Output(), "%s: ожидается ID задачи\n", fs.Name())
+		return 0, errUsage
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		fmt.Fprintf(fs.Output(), "%s: некорректный ID задачи %q\n", fs.Name(), raw)
+		return 0, errUsage
+	}
+	return id, nil
+}
+
+func formatFlag(fs *flag.FlagSet, def Format) *Format {
+	format := def
+	fs.Func("format", "формат вывода: table, json, csv (по умолчанию "+string(def)+")", func(value string) error {
+		f, err := ParseFormat(value)
+		if err != nil {
+			return err
+		}
+		format = f
+		return nil
+	})
+	return &format
+}
+
+// taskFields флаги полей задачи для add и edit
+type taskFields struct {
+	title, description, priority, status, due *string
+}
+
+// versionFlag версия задачи из ETag, с которой сверяется изменение; 0 — текущая версия на сервере
+func versionFlag(fs *flag.FlagSet) *int64 {
+	return fs.Int64("version", 0, "версия задачи (ETag); по умолчанию текущая версия на сервере")
+}
+
+func taskFlags(fs *flag.FlagSet) *taskFields {
+	return &taskFields{
+		title:       fs.String("title", "", "название"),
+		description: fs.String("description", "", "описание"),
+		priority:    fs.String("priority", "", "приоритет: low, medium, high"),
+		status:      fs.String("status", "", "статус"),
+		due:         fs.String("due", "", "срок: YYYY-MM-DD или RFC3339"),
+	}
+}
+
+// values возвращает только явно переданные поля, чтобы edit не затирал остальные
+func (f *taskFields) values(fs *flag.FlagSet) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	var err error
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "title":
+			values["title"] = *f.title
+		case "description":
+			values["description"] = *f.description
+		case "priority":
+			values["priority"] = *f.priority
+		case "status":
+			values["status"] = *f.status
+		case "due":
+			var due string
+			if due, err = parseDate(*f.due); err == nil {
+				values["due_date"] = due
+			}
+		}
+	})
+	return values, err
+}
+
+// parseDate приводит дату YYYY-MM-DD или RFC3339 к RFC3339, который принимает API
+func parseDate(value string) (string, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+	return "", fmt.Errorf("некорректная дата %q: ожидается YYYY-MM-DD или RFC3339", value)
+}
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"GoTasker/internal/domain"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runCLI выполняет команду с настройками в configPath
+func runCLI(t *testing.T, configPath string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = Run(context.Background(), append([]string{"-config", configPath}, args...), strings.NewReader(""), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+// loggedIn запускает фейковый API и входит в него
+func loggedIn(t *testing.T, handler http.HandlerFunc) (*fakeAPI, string) {
+	api, srv := newFakeAPI(t, handler)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	code, stdout, stderr := runCLI(t, configPath, "login", "-server", srv.URL, "-email", "user@example.com", "-password", "secret")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, srv.URL)
+
+	return api, configPath
+}
+
+func testTasks(n int) []*domain.Task {
+	due := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	tasks := make([]*domain.Task, n)
+	for i := range tasks {
+		tasks[i] = &domain.Task{ID: int64(i + 1), Title: fmt.Sprintf("задача %d", i+1), Status: domain.StatusPending, Priority: domain.PriorityHigh, DueDate: due, Version: 1}
+	}
+	return tasks
+}
+
+func TestRun_List(t *testing.T) {
+	tasks := testTasks(5)
+	api, configPath := loggedIn(t, func(w http.ResponseWriter, r *http.Request) {
+		// Страницы по limit задач, курсор — смещение
+		offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(tasks))
+
+		page := domain.TaskPage{Items: tasks[offset:end], Total: len(tasks)}
+		if end < len(tasks) {
+			page.NextCursor = strconv.Itoa(end)
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	t.Run("фильтры передаются в запрос, страницы собираются до limit", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, configPath, "list", "-status", "pending,in_progress", "-overdue", "-sort", "-due_date", "-limit", "3", "-format", "csv")
+		require.Equal(t, 0, code, stderr)
+
+		records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, csvHeader, records[0])
+		assert.Equal(t, []string{"1", "задача 1", "", "pending", "high", "2025-03-01T00:00:00Z", "", "", "1"}, records[1])
+
+		query := api.requests[len(api.requests)-1].URL.Query()
+		assert.Equal(t, "pending,in_progress", query.Get("status"))
+		assert.Equal(t, "true", query.Get("overdue"))
+		assert.Equal(t, "-due_date", query.Get("sort"))
+		assert.Equal(t, "3", query.Get("limit"))
+	})
+
+	t.Run("-all проходит по всем страницам", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, configPath, "list", "-all", "-format", "json")
+		require.Equal(t, 0, code, stderr)
+
+		var got []*domain.Task
+		require.NoError(t, json.Unmarshal([]byte(stdout), &got))
+		assert.Len(t, got, 5)
+	})
+
+	t.Run("таблица", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, configPath, "list", "-limit", "1")
+		require.Equal(t, 0, code)
+		assert.Equal(t, "ID  STATUS   PRIORITY  DUE         TITLE\n1   pending  high      2025-03-01  задача 1\n", stdout)
+	})
+
+	t.Run("неизвестный формат", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "list", "-format", "xml")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "xml")
+	})
+}
+
+func TestRun_ChangeTasks(t *testing.T) {
+	api, configPath := loggedIn(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPost:
+			if r.URL.Path == "/tasks/import" {
+				// Ответ в том виде, в каком его отдаёт TaskHandler.ImportTasks
+				_, _ = w.Write([]byte(`{"message":"Импорт успешно завершен","inserted_tasks":2,"skipped_tasks":["Задача 3: название задачи не может быть пустым"]}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(testTasks(1)[0])
+		default:
+			_ = json.NewEncoder(w).Encode(testTasks(1)[0])
+		}
+	})
+	last := func() (*http.Request, map[string]interface{}) {
+		var body map[string]interface{}
+		_ = json.Unmarshal(api.bodies[len(api.bodies)-1], &body)
+		return api.requests[len(api.requests)-1], body
+	}
+
+	t.Run("add", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "add", "-title", "отчёт", "-priority", "high", "-due", "2025-03-01")
+		require.Equal(t, 0, code, stderr)
+
+		req, body := last()
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, map[string]interface{}{"title": "отчёт", "priority": "high", "due_date": "2025-03-01T00:00:00Z"}, body)
+	})
+
+	t.Run("add без названия", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "add", "-priority", "high")
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr, "-title")
+	})
+
+	t.Run("edit меняет только переданные поля", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "edit", "7", "-description", "", "-status", "in_progress")
+		require.Equal(t, 0, code, stderr)
+
+		req, body := last()
+		assert.Equal(t, http.MethodPatch, req.Method)
+		assert.Equal(t, "/tasks/7", req.URL.Path)
+		assert.Equal(t, `"1"`, req.Header.Get("If-Match"), "версия прочитана перед изменением")
+		assert.Equal(t, "application/merge-patch+json", req.Header.Get("Content-Type"))
+		assert.Equal(t, map[string]interface{}{"description": "", "status": "in_progress"}, body)
+
+		read := api.requests[len(api.requests)-2]
+		assert.Equal(t, http.MethodGet, read.Method)
+		assert.Equal(t, "/tasks/7", read.URL.Path)
+	})
+
+	t.Run("edit с -version не читает задачу", func(t *testing.T) {
+		before := len(api.requests)
+		code, _, stderr := runCLI(t, configPath, "edit", "7", "-title", "новое", "-version", "4")
+		require.Equal(t, 0, code, stderr)
+
+		require.Len(t, api.requests, before+1)
+		req, _ := last()
+		assert.Equal(t, `"4"`, req.Header.Get("If-Match"))
+	})
+
+	t.Run("done", func(t *testing.T) {
+		code, _, stderr := runCLI(t, configPath, "done", "-format", "json", "7")
+		require.Equal(t, 0, code, stderr)
+
+		req, body := last()
+		assert.Equal(t, "/tasks/7", req.URL.Path)
+		assert.Equal(t, map[string]interface{}{"status": "done"}, body)
+	})
+
+	t.Run("rm", func(t *testing.T) {
+		code, stdout, stderr := runCLI(t, configPath, "rm", "7", "-permanent")
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "безвозвратно")
+
+		req, _ := last()
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, "true", req.URL.Query().Get("permanent"))
+	})
+
+	t.Run("некорректный ID", func(t *testing.T) {
+		code, _, _ := runCLI(t, configPath, "rm", "abc")
+		assert.Equal(t, 2, code)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tasks.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"title":"a"}]`), 0o600))
+
+		code, stdout, stderr := runCLI(t, configPath, "import", path)
+		require.Equal(t, 0, code, stderr)
+		assert.Equal(t, "Добавлено задач: 2, пропущено: 1\n  Задача 3: название задачи не может быть пустым\n", stdout)
+
+		req, _ := last()
+		assert.True(t, strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data"))
+		assert.Contains(t, string(api.bodies[len(api.bodies)-1]), `[{"title":"a"}]`)
+	})
+}
+
+func TestRun_Export(t *testing.T) {
+	tasks := testTasks(2)
+	_, configPath := loggedIn(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(tasks)
+	})
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	code, _, stderr := runCLI(t, configPath, "export", "-o", path)
+	require.Equal(t, 0, code, stderr)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+
+	var got []*domain.Task
+	require.NoError(t, json.Unmarshal(data, &got), "экспорт в JSON подходит для import")
+	assert.Len(t, got, 2)
+}
+
+func TestRun_Usage(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	code, _, stderr := runCLI(t, configPath)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "использование")
+
+	code, _, _ = runCLI(t, configPath, "unknown")
+	assert.Equal(t, 2, code)
+
+	code, _, stderr = runCLI(t, configPath, "list")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "gotasker login")
+}
+
+func TestRun_EditVersionConflict(t *testing.T) {
+	_, configPath := loggedIn(t, func(w http.ResponseWriter, r *http.Request) {
+		current := testTasks(1)[0]
+		current.Version = 5
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": http.StatusPreconditionFailed, "title": "Precondition Failed", "code": "version_mismatch", "current": current,
+		})
+	})
+
+	code, stdout, stderr := runCLI(t, configPath, "done", "1", "-version", "3")
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "задача 1 изменена другим запросом: ожидалась версия 3, текущая 5")
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DefaultServer адрес API, если при входе не указан другой
+const DefaultServer = "http://localhost:8085"
+
+// Config настройки CLI: адрес API и токены текущей сессии. Хранится в JSON-файле, доступном только владельцу
+type Config struct {
+	Server       string `json:"server"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	path string
+}
+
+// DefaultConfigPath путь к файлу настроек: GOTASKER_CONFIG или <каталог настроек пользователя>/gotasker/config.json
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv("GOTASKER_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+
+	return filepath.Join(dir, "gotasker", "config.json"), nil
+}
+
+// LoadConfig читает настройки из path; если файла нет, возвращает настройки по умолчанию
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Server: DefaultServer, path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения настроек %s: %w", path, err)
+	}
+
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("ошибка разбора настроек %s: %w", path, err)
+	}
+	if cfg.Server == "" {
+		cfg.Server = DefaultServer
+	}
+
+	return cfg, nil
+}
+
+// Save записывает настройки. Файл заменяется целиком, чтобы прерванная запись не потеряла токены
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("ошибка создания каталога настроек: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".config-*.json")
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения настроек: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("ошибка сохранения настроек: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка сохранения настроек: %w", err)
+	}
+
+	// CreateTemp создаёт файл с правами 0600
+	if err = os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("ошибка сохранения настроек: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"GoTasker/internal/domain"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Format формат вывода задач
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(value); f {
+	case FormatTable, FormatJSON, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат %q: допустимы table, json, csv", value)
+	}
+}
+
+// csvHeader колонки CSV; порядок совпадает с csvRecord
+var csvHeader = []string{"id", "title", "description", "status", "priority", "due_date", "created_at", "updated_at", "version"}
+
+// WriteTasks выводит задачи в формате format. JSON совпадает с форматом экспорта и годится для import
+func WriteTasks(w io.Writer, format Format, tasks []*domain.Task) error {
+	switch format {
+	case FormatJSON:
+		if tasks == nil {
+			tasks = []*domain.Task{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tasks)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err := cw.Write(csvRecord(task)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSTATUS\tPRIORITY\tDUE\tTITLE")
+		for _, task := range tasks {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", task.ID, task.Status, task.Priority, formatDate(task.DueDate), task.Title)
+		}
+		return tw.Flush()
+	}
+}
+
+// WriteTask выводит одну задачу; в JSON — объектом, а не массивом
+func WriteTask(w io.Writer, format Format, task *domain.Task) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(task)
+	}
+	return WriteTasks(w, format, []*domain.Task{task})
+}
+
+func csvRecord(task *domain.Task) []string {
+	return []string{
+		strconv.FormatInt(task.ID, 10),
+		task.Title,
+		task.Description,
+		string(task.Status),
+		string(task.Priority),
+		formatTime(task.DueDate),
+		formatTime(task.CreatedAt),
+		formatTime(task.UpdatedAt),
+		strconv.FormatInt(task.Version, 10),
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatDate выводит срок в таблице: дату, если время — полночь UTC, иначе дату со временем
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format("2006-01-02 15:04")
+}
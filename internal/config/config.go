@@ -1,40 +1,51 @@
 package config
 
 import (
+	"GoTasker/internal/i18n"
+	"GoTasker/internal/scheduler"
 	"fmt"
 	"github.com/joho/godotenv"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Config содержит все конфигурационные параметры приложения
 type Config struct {
-	DB     DBConfig     // Настройки базы данных
-	Server ServerConfig // Настройки сервера
-	Log    LogConfig    // Настройки логирования
-	Redis  RedisConfig  // Настройки Redis
-	Env    string       // Текущее окружение (development, production, test)
+	DB       DBConfig       // Настройки базы данных
+	Server   ServerConfig   // Настройки сервера
+	Log      LogConfig      // Настройки логирования
+	Redis    RedisConfig    // Настройки Redis
+	Workflow WorkflowConfig // Настройки статусов задач
+	Jobs     JobsConfig     // Настройки фоновых задач
+	Env      string         // Текущее окружение (development, production, test)
 }
 
 // DBConfig содержит параметры подключения к базе данных
 type DBConfig struct {
-	Host     string // Хост базы данных
-	Port     int    // Порт базы данных
-	User     string // Имя пользователя
-	Password string // Пароль
-	DBName   string // Название базы данных
-	SSLMode  string // Режим SSL-подключения
+	Host        string // Хост базы данных
+	Port        int    // Порт базы данных
+	User        string // Имя пользователя
+	Password    string // Пароль
+	DBName      string // Название базы данных
+	SSLMode     string // Режим SSL-подключения
+	AutoMigrate bool   // Применять миграции при запуске приложения
 }
 
 // ServerConfig содержит настройки HTTP-сервера
 type ServerConfig struct {
-	Port            string        // Порт для HTTP-сервера
-	JWTSecret       string        // Ключ для JWT токена
-	RefreshDuration time.Duration // ttl refresh токена
-	AccessDuration  time.Duration // ttl access токена
-	TaskCleanupDays int           // Время для фоновой джобы очиски задач
+	Port               string        // Порт для HTTP-сервера
+	MetricsAddr        string        // Адрес отдельного listener'а для /metrics; по умолчанию только localhost
+	JWTSecret          string        // Ключ для JWT токена
+	RefreshDuration    time.Duration // ttl refresh токена
+	AccessDuration     time.Duration // ttl access токена
+	TaskCleanupDays    int           // Через сколько дней после срока просроченная задача перемещается в корзину
+	TrashRetentionDays int           // Сколько дней задача хранится в корзине до безвозвратного удаления
+	DefaultLanguage    string        // Язык сообщений API, если клиент не передал поддерживаемый Accept-Language
+	AdminUserIDs       []int64       // ID пользователей-администраторов: им доступны эндпоинты /admin
+	ShutdownTimeout    time.Duration // Сколько ждать завершения запросов и фоновых задач при остановке
 }
 
 // RedisConfig содержит настройки подключения к Redis
@@ -46,6 +57,22 @@ type RedisConfig struct {
 	TTL      time.Duration // Время жизни кэша
 }
 
+// WorkflowConfig содержит набор статусов задач и разрешённые переходы между ними
+type WorkflowConfig struct {
+	InitialStatus string // Статус новой задачи, если он не указан
+	Transitions   string // Переходы вида "pending:in_progress,done;in_progress:pending,done;done:in_progress"
+}
+
+// JobsConfig содержит расписания фоновых задач в формате cron (UTC) и параметры их запуска
+type JobsConfig struct {
+	TaskCleanupSchedule   string        // Перемещение просроченных задач в корзину
+	TrashPurgeSchedule    string        // Безвозвратное удаление задач из корзины
+	DailySnapshotSchedule string        // Снимок количества задач для burndown
+	Timeout               time.Duration // Таймаут одной попытки
+	Retries               int           // Повторные попытки после ошибки
+	Backoff               time.Duration // Пауза перед первой повторной попыткой, дальше удваивается
+}
+
 // LogConfig содержит настройки логирования
 type LogConfig struct {
 	Level       string // Уровень логирования (DEBUG, INFO, WARN, ERROR)
@@ -90,19 +117,37 @@ func NewConfig() *Config {
 			TTL:      time.Duration(getEnvAsInt("REDIS_CACHE_TTL", 15)) * time.Minute,
 		},
 		DB: DBConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "postgres"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:        getEnv("DB_HOST", "localhost"),
+			Port:        getEnvAsInt("DB_PORT", 5432),
+			User:        getEnv("DB_USER", "postgres"),
+			Password:    getEnv("DB_PASSWORD", "postgres"),
+			DBName:      getEnv("DB_NAME", "postgres"),
+			SSLMode:     getEnv("DB_SSLMODE", "disable"),
+			AutoMigrate: getEnvAsBool("DB_AUTO_MIGRATE", false),
 		},
 		Server: ServerConfig{
-			Port:            getEnv("SERVER_PORT", ":8080"),
-			JWTSecret:       getEnv("JWT_SECRET", "secret"),
-			AccessDuration:  time.Duration(getEnvAsInt("ACCESS_DURATION", 15)),  // minutes
-			RefreshDuration: time.Duration(getEnvAsInt("REFRESH_DURATION", 30)), // days
-			TaskCleanupDays: getEnvAsInt("TASK_CLEANUP_DAYS", 7),
+			Port:               getEnv("SERVER_PORT", ":8080"),
+			MetricsAddr:        getEnv("METRICS_ADDR", "127.0.0.1:9090"),
+			JWTSecret:          getEnv("JWT_SECRET", "secret"),
+			AccessDuration:     time.Duration(getEnvAsInt("ACCESS_DURATION", 15)) * time.Minute,
+			RefreshDuration:    time.Duration(getEnvAsInt("REFRESH_DURATION", 30)) * 24 * time.Hour,
+			TaskCleanupDays:    getEnvAsInt("TASK_CLEANUP_DAYS", 7),
+			TrashRetentionDays: getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+			DefaultLanguage:    getEnv("DEFAULT_LANGUAGE", "ru"),
+			AdminUserIDs:       getEnvAsIDList("ADMIN_USER_IDS"),
+			ShutdownTimeout:    time.Duration(getEnvAsInt("SHUTDOWN_TIMEOUT", 30)) * time.Second,
+		},
+		Jobs: JobsConfig{
+			TaskCleanupSchedule:   getEnv("TASK_CLEANUP_SCHEDULE", "@hourly"),
+			TrashPurgeSchedule:    getEnv("TRASH_PURGE_SCHEDULE", "30 * * * *"),
+			DailySnapshotSchedule: getEnv("DAILY_SNAPSHOT_SCHEDULE", "55 23 * * *"),
+			Timeout:               time.Duration(getEnvAsInt("JOB_TIMEOUT", 5)) * time.Minute,
+			Retries:               getEnvAsInt("JOB_RETRIES", 3),
+			Backoff:               time.Duration(getEnvAsInt("JOB_BACKOFF", 10)) * time.Second,
+		},
+		Workflow: WorkflowConfig{
+			InitialStatus: getEnv("TASK_INITIAL_STATUS", "pending"),
+			Transitions:   getEnv("TASK_STATUS_TRANSITIONS", "pending:in_progress,done;in_progress:pending,done;done:in_progress"),
 		},
 		Log: LogConfig{
 			Level:       getEnv("LOG_LEVEL", "INFO"),
@@ -136,6 +181,31 @@ func (c *Config) Validate() error {
 	if c.Server.Port == "" {
 		return fmt.Errorf("порт сервера не может быть пустым")
 	}
+	if c.Server.MetricsAddr == c.Server.Port {
+		return fmt.Errorf("метрики должны отдаваться на адресе, отличном от порта сервера")
+	}
+	if c.Server.TaskCleanupDays <= 0 {
+		return fmt.Errorf("срок до очистки просроченных задач должен быть положительным")
+	}
+	if c.Server.TrashRetentionDays <= 0 {
+		return fmt.Errorf("срок хранения корзины должен быть положительным")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("таймаут остановки сервера должен быть положительным")
+	}
+	if !i18n.Lang(c.Server.DefaultLanguage).Supported() {
+		return fmt.Errorf("неподдерживаемый язык по умолчанию: %s", c.Server.DefaultLanguage)
+	}
+
+	// Проверка настроек фоновых задач
+	for _, spec := range []string{c.Jobs.TaskCleanupSchedule, c.Jobs.TrashPurgeSchedule, c.Jobs.DailySnapshotSchedule} {
+		if _, err := scheduler.ParseSchedule(spec); err != nil {
+			return err
+		}
+	}
+	if c.Jobs.Timeout <= 0 || c.Jobs.Retries < 0 || c.Jobs.Backoff < 0 {
+		return fmt.Errorf("некорректные таймаут или повторы фоновых задач")
+	}
 
 	// Проверка настроек логирования
 	validLogLevels := map[string]bool{"DEBUG": true, "INFO": true, "WARN": true, "ERROR": true}
@@ -168,13 +238,38 @@ func getEnv(key, defaultValue string) string {
 
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
-		if i, err := fmt.Sscanf(value, "%d"); err == nil {
+		if i, err := strconv.Atoi(value); err == nil {
 			return i
 		}
 	}
 	return defaultValue
 }
 
+// getEnvAsIDList разбирает список положительных ID через запятую
+func getEnvAsIDList(key string) []int64 {
+	items := getEnvAsList(key)
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil || id <= 0 {
+			panic(fmt.Sprintf("невалидная конфигурация: некорректный ID %q в %s", item, key))
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// getEnvAsList разбирает список через запятую, пропуская пустые элементы
+func getEnvAsList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		return strings.ToLower(value) == "true"
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"GoTasker/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware пускает только администраторов: пользователей, чей ID указан в конфигурации (ADMIN_USER_IDS).
+// Проверяется ID из подписанного токена, а не email: email выбирает сам пользователь при регистрации
+type AdminMiddleware struct {
+	userIDs map[int64]bool
+}
+
+func NewAdminMiddleware(userIDs []int64) *AdminMiddleware {
+	m := &AdminMiddleware{userIDs: make(map[int64]bool, len(userIDs))}
+	for _, id := range userIDs {
+		m.userIDs[id] = true
+	}
+	return m
+}
+
+// RequireAdmin проверяет, что пользователь запроса — администратор. Ставится после RequireAuth
+func (m *AdminMiddleware) RequireAdmin(c *gin.Context) {
+	user, ok := domain.UserFromContext(c.Request.Context())
+	if !ok {
+		abortWithError(c, domain.ErrUnauthenticated)
+		return
+	}
+
+	if !m.userIDs[user.ID] {
+		abortWithError(c, domain.ErrAdminRequired)
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
+	"GoTasker/pkg/utils"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+
+	// ContextUserKey ключ, под которым пользователь сохраняется в gin.Context
+	ContextUserKey = "user"
+)
+
+type TokenRevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error)
+}
+
+type AuthMiddleware struct {
+	jwtSecret    []byte
+	tokenChecker TokenRevocationChecker
+}
+
+func NewAuthMiddleware(jwtSecret string, tokenChecker TokenRevocationChecker) *AuthMiddleware {
+	return &AuthMiddleware{
+		jwtSecret:    []byte(jwtSecret),
+		tokenChecker: tokenChecker,
+	}
+}
+
+// RequireAuth проверяет access токен из заголовка Authorization и
+// кладёт аутентифицированного пользователя в контекст запроса
+func (m *AuthMiddleware) RequireAuth(c *gin.Context) {
+	const op = "internal.delivery.http.middleware.RequireAuth"
+
+	header := c.GetHeader(authorizationHeader)
+	if header == "" {
+		abortWithError(c, domain.ErrTokenMissing)
+		return
+	}
+
+	if !strings.HasPrefix(header, bearerPrefix) {
+		abortWithError(c, domain.NewError(domain.ErrUnauthorized, domain.CodeInvalidToken, i18n.InvalidAuthorizationHeader))
+		return
+	}
+
+	encodedToken := strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix))
+	claims, err := utils.ParseAccessToken(encodedToken, m.jwtSecret)
+	if err != nil {
+		slog.Debug(op, "токен отклонён", slog.String("err", err.Error()))
+
+		switch {
+		case errors.Is(err, utils.ErrTokenExpired):
+			abortWithError(c, domain.ErrTokenExpired)
+		case errors.Is(err, utils.ErrWrongTokenType):
+			abortWithError(c, domain.ErrWrongTokenType)
+		default:
+			abortWithError(c, domain.ErrInvalidToken)
+		}
+		return
+	}
+
+	if claims.UserID == 0 || claims.ID == "" || claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		abortWithError(c, domain.ErrInvalidToken)
+		return
+	}
+
+	revoked, err := m.tokenChecker.IsTokenRevoked(c.Request.Context(), claims.ID, claims.UserID, claims.IssuedAtTime())
+	if err != nil {
+		slog.Error(op, "не удалось проверить отзыв токена", slog.String("err", err.Error()))
+		abortWithError(c, domain.ErrTokenCheckUnavailable)
+		return
+	}
+	if revoked {
+		abortWithError(c, domain.ErrTokenRevoked)
+		return
+	}
+
+	user := &domain.AuthUser{
+		ID:             claims.UserID,
+		Username:       claims.Username,
+		Email:          claims.Email,
+		TokenID:        claims.ID,
+		SessionID:      claims.SessionID,
+		TokenExpiresAt: claims.ExpiresAt.Time,
+	}
+
+	c.Set(ContextUserKey, user)
+	c.Request = c.Request.WithContext(domain.ContextWithUser(c.Request.Context(), user))
+
+	c.Next()
+}
+
+// abortWithError прерывает цепочку обработчиков; ответ формирует ErrorHandler
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
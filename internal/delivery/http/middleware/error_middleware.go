@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// ProblemContentType тип содержимого ответа об ошибке (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem тело ответа об ошибке в формате RFC 7807
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`              // Стабильный машиночитаемый код ошибки
+	Field    string       `json:"field,omitempty"`   // Поле или параметр запроса с ошибкой валидации
+	Details  []string     `json:"details,omitempty"` // Подробности, например отклонённые при импорте задачи
+	Current  *domain.Task `json:"current,omitempty"` // Актуальное состояние задачи при конфликте версий
+}
+
+const codeInternal = "internal_error"
+
+// statusByKind код ответа для каждой категории доменных ошибок
+var statusByKind = []struct {
+	kind   error
+	status int
+}{
+	{domain.ErrValidation, http.StatusBadRequest},
+	{domain.ErrUnauthorized, http.StatusUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden},
+	{domain.ErrNotFound, http.StatusNotFound},
+	{domain.ErrConflict, http.StatusConflict},
+	{domain.ErrUnavailable, http.StatusServiceUnavailable},
+}
+
+// statusByCode уточняет код ответа для ошибок, у которых в HTTP есть отдельный статус
+var statusByCode = map[string]int{
+	domain.CodeVersionMismatch:      http.StatusPreconditionFailed,
+	domain.CodeIfMatchRequired:      http.StatusPreconditionRequired,
+	domain.CodeUnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// ErrorHandler превращает последнюю ошибку, добавленную обработчиком через c.Error,
+// в ответ application/problem+json. Обработчики не выбирают код ответа сами
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		problem := NewProblem(err, i18n.FromContext(c.Request.Context()))
+		problem.Instance = c.Request.URL.Path
+
+		if problem.Status >= http.StatusInternalServerError {
+			slog.Error("internal.delivery.http.middleware.ErrorHandler", "ошибка обработки запроса",
+				slog.String("path", c.Request.URL.Path),
+				slog.String("err", err.Error()),
+			)
+		}
+
+		if problem.Current != nil {
+			c.Header("ETag", strconv.Quote(strconv.FormatInt(problem.Current.Version, 10)))
+		}
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// NewProblem сопоставляет ошибке код ответа и тело problem+json с сообщением на языке lang.
+// Ошибки вне доменной модели считаются внутренними, их текст клиенту не отдаётся
+func NewProblem(err error, lang i18n.Lang) *Problem {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return newProblem(http.StatusInternalServerError, codeInternal, i18n.T(lang, i18n.InternalError))
+	}
+
+	status := http.StatusInternalServerError
+	for _, s := range statusByKind {
+		if errors.Is(domainErr.Kind, s.kind) {
+			status = s.status
+			break
+		}
+	}
+	if s, ok := statusByCode[domainErr.Code]; ok {
+		status = s
+	}
+
+	problem := newProblem(status, domainErr.Code, domainErr.Localize(lang))
+	problem.Field = domainErr.Field
+	problem.Details = domainErr.Details
+
+	var conflict *domain.VersionConflictError
+	if errors.As(err, &conflict) {
+		problem.Current = conflict.Current
+	}
+
+	return problem
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
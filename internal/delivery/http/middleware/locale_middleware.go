@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"GoTasker/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+type LocaleMiddleware struct {
+	defaultLang i18n.Lang
+}
+
+func NewLocaleMiddleware(defaultLang i18n.Lang) *LocaleMiddleware {
+	return &LocaleMiddleware{
+		defaultLang: defaultLang,
+	}
+}
+
+// DetectLanguage выбирает язык ответа по заголовку Accept-Language и кладёт его в контекст запроса
+func (m *LocaleMiddleware) DetectLanguage(c *gin.Context) {
+	lang := i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language"), m.defaultLang)
+
+	c.Request = c.Request.WithContext(i18n.ContextWithLang(c.Request.Context(), lang))
+	c.Header("Content-Language", string(lang))
+	c.Header("Vary", "Accept-Language")
+
+	c.Next()
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"GoTasker/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute метка маршрута для запросов, не попавших ни в один маршрут: путь в метку не пишем,
+// чтобы случайные URL не раздували число временных рядов
+const unmatchedRoute = "unmatched"
+
+// Metrics учитывает количество и время обработки запросов по шаблону маршрута (/tasks/:id), а не по фактическому пути
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
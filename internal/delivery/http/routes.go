@@ -1,8 +1,11 @@
 package http
 
 import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/handler/admin"
 	"GoTasker/internal/handler/analytics"
 	"GoTasker/internal/handler/auth"
+	"GoTasker/internal/handler/health"
 	"GoTasker/internal/handler/tasks"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -14,27 +17,56 @@ func SetupRoutes(r *gin.Engine,
 	taskHandler *tasks.TaskHandler,
 	analyticHandler *analytics.TaskAnalyticsHandler,
 	authHandler *auth.UserAuthHandler,
+	adminHandler *admin.AdminHandler,
+	healthHandler *health.HealthHandler,
+	authMiddleware *middleware.AuthMiddleware,
+	adminMiddleware *middleware.AdminMiddleware,
+	localeMiddleware *middleware.LocaleMiddleware,
 ) {
-	taskGroup := r.Group("/tasks")
+	r.Use(middleware.Metrics())            // Метрики HTTP-запросов для Prometheus
+	r.Use(localeMiddleware.DetectLanguage) // Язык сообщений по Accept-Language
+	r.Use(middleware.ErrorHandler())       // Ошибки обработчиков в формате application/problem+json
+
+	r.GET("/healthz", healthHandler.Liveness) // Liveness: процесс жив
+	r.GET("/readyz", healthHandler.Readiness) // Readiness: PostgreSQL, схема и Redis
+
+	taskGroup := r.Group("/tasks", authMiddleware.RequireAuth)
 	{
-		taskGroup.GET("", taskHandler.GetAll)        // Получение списка задач
-		taskGroup.POST("", taskHandler.Create)       // Создание задачи
-		taskGroup.PUT("/:id", taskHandler.Update)    // Обновление задачи
-		taskGroup.DELETE("/:id", taskHandler.Delete) // Удаление задачи
+		taskGroup.GET("", taskHandler.GetAll)               // Получение списка задач
+		taskGroup.POST("", taskHandler.Create)              // Создание задачи
+		taskGroup.GET("/:id", taskHandler.GetByID)          // Получение задачи по ID
+		taskGroup.PUT("/:id", taskHandler.Update)           // Полная замена задачи
+		taskGroup.PATCH("/:id", taskHandler.Patch)          // Частичное обновление задачи (JSON Merge Patch)
+		taskGroup.DELETE("/:id", taskHandler.Delete)        // Удаление задачи
+		taskGroup.GET("/:id/history", taskHandler.History)  // История изменений задачи
+		taskGroup.POST("/:id/restore", taskHandler.Restore) // Восстановление задачи из корзины
 
 		taskGroup.POST("/import", taskHandler.Import) // Импорт задач
 		taskGroup.GET("/export", taskHandler.Export)  // Экспорт задач
+		taskGroup.GET("/search", taskHandler.Search)  // Полнотекстовый поиск задач
+		taskGroup.GET("/trash", taskHandler.Trash)    // Корзина
+	}
+
+	analyticGroup := r.Group("/analytics", authMiddleware.RequireAuth)
+	{
+		analyticGroup.GET("", analyticHandler.GetAnalytics)             // Получение аналитики
+		analyticGroup.GET("/timeseries", analyticHandler.GetTimeSeries) // Временной ряд для графиков
+		analyticGroup.GET("/burndown", analyticHandler.GetBurndown)     // Данные burndown и burnup
 	}
 
-	analyticGroup := r.Group("/analytics")
+	adminGroup := r.Group("/admin", authMiddleware.RequireAuth, adminMiddleware.RequireAdmin)
 	{
-		analyticGroup.GET("", analyticHandler.GetAnalytics) // Получение аналитики
+		adminGroup.GET("/jobs", adminHandler.Jobs) // Фоновые задачи: последний и ближайший запуск
 	}
 
 	authGroup := r.Group("/auth")
 	{
 		authGroup.POST("/register", authHandler.Register) // Регистрация пользователя
 		authGroup.POST("/login", authHandler.Login)       // Вход и получение JWT (access and refresh)
+		authGroup.POST("/refresh", authHandler.Refresh)   // Обмен refresh токена на новую пару токенов
+
+		authGroup.POST("/logout", authMiddleware.RequireAuth, authHandler.Logout)        // Завершение текущей сессии
+		authGroup.POST("/logout-all", authMiddleware.RequireAuth, authHandler.LogoutAll) // Завершение всех сессий пользователя
 	}
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	"GoTasker/internal/handler/admin"
+	"GoTasker/pkg/utils"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeScheduler возвращает заранее заданный список фоновых задач
+type fakeScheduler struct {
+	jobs []domain.JobInfo
+}
+
+func (s *fakeScheduler) Jobs(ctx context.Context) []domain.JobInfo {
+	return s.jobs
+}
+
+func TestAdminJobs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	next := time.Date(2025, 3, 1, 13, 0, 0, 0, time.UTC)
+	scheduler := &fakeScheduler{jobs: []domain.JobInfo{{
+		Name:     "task_cleanup",
+		Schedule: "@hourly",
+		NextRun:  next,
+		LastRun:  &domain.JobRun{Status: domain.JobRunFailed, Attempts: 4, Error: "timeout"},
+	}}}
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	authMiddleware := middleware.NewAuthMiddleware(testJWTSecret, &fakeRevocationChecker{})
+	adminMiddleware := middleware.NewAdminMiddleware([]int64{1})
+	router.GET("/admin/jobs", authMiddleware.RequireAuth, adminMiddleware.RequireAdmin, admin.NewAdminHandler(scheduler).Jobs)
+
+	request := func(userID int64, email string) *httptest.ResponseRecorder {
+		token, err := utils.GenerateAccessToken(userID, "user", email, "session", testJWTSecret, time.Minute)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("администратор видит задачи", func(t *testing.T) {
+		w := request(1, "admin@example.com")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Jobs []domain.JobInfo `json:"jobs"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Jobs, 1)
+		assert.Equal(t, next, body.Jobs[0].NextRun)
+		assert.Equal(t, domain.JobRunFailed, body.Jobs[0].LastRun.Status)
+	})
+
+	forbidden := []struct {
+		name   string
+		userID int64
+		email  string
+	}{
+		{"обычному пользователю доступ запрещён", 2, "john@example.com"},
+		// Email уникален с учётом регистра, поэтому такой адрес можно зарегистрировать вторым аккаунтом
+		{"аккаунту с email администратора в другом регистре доступ запрещён", 3, "ADMIN@example.com"},
+		{"аккаунту с тем же email, но другим ID доступ запрещён", 4, "admin@example.com"},
+	}
+	for _, tt := range forbidden {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.userID, tt.email)
+			require.Equal(t, http.StatusForbidden, w.Code)
+
+			var problem middleware.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, domain.CodeAdminRequired, problem.Code)
+		})
+	}
+}
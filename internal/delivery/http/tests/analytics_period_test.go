@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	handler "GoTasker/internal/handler/analytics"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// periodRecordingUseCase запоминает период и размер интервала, с которыми вызвана аналитика
+type periodRecordingUseCase struct {
+	period     *domain.AnalyticsPeriod
+	bucket     domain.TimeBucket
+	priorities []domain.Priority
+}
+
+func (uc *periodRecordingUseCase) GetAnalytics(ctx context.Context, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error) {
+	uc.period = period
+	return &domain.AnalyticsTasksResponse{}, nil
+}
+
+func (uc *periodRecordingUseCase) GetTimeSeries(ctx context.Context, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) (*domain.TimeSeries, error) {
+	uc.period, uc.bucket = period, bucket
+	return &domain.TimeSeries{Bucket: bucket, Points: []domain.TimeSeriesPoint{}}, nil
+}
+
+func (uc *periodRecordingUseCase) GetBurndown(ctx context.Context, period *domain.AnalyticsPeriod, priorities []domain.Priority) (*domain.Burndown, error) {
+	uc.period, uc.priorities = period, priorities
+	return &domain.Burndown{Priorities: priorities, Points: []domain.BurndownPoint{}}, nil
+}
+
+func setupAnalyticsRouter(uc *periodRecordingUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	analyticsHandler := handler.NewAnalyticsHandler(uc)
+
+	router.GET("/analytics", analyticsHandler.GetAnalytics)
+	router.GET("/analytics/timeseries", analyticsHandler.GetTimeSeries)
+	router.GET("/analytics/burndown", analyticsHandler.GetBurndown)
+
+	return router
+}
+
+func TestAnalyticsAPI_Period(t *testing.T) {
+	uc := &periodRecordingUseCase{}
+	router := setupAnalyticsRouter(uc)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("по умолчанию последние 7 дней", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, get("/analytics").Code)
+		assert.Equal(t, 7*24*time.Hour, uc.period.To.Sub(uc.period.From))
+		assert.Equal(t, "period=7d", uc.period.CacheKey())
+	})
+
+	t.Run("period=90d", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, get("/analytics?period=90d").Code)
+		assert.Equal(t, "period=90d", uc.period.CacheKey())
+	})
+
+	t.Run("from и to, дата в to включается целиком", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, get("/analytics?from=2025-03-01&to=2025-03-31").Code)
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), uc.period.From)
+		assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), uc.period.To)
+	})
+
+	t.Run("временной ряд по неделям", func(t *testing.T) {
+		w := get("/analytics/timeseries?bucket=week&period=30d")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.BucketWeek, uc.bucket)
+		assert.Equal(t, "period=30d", uc.period.CacheKey())
+	})
+
+	t.Run("burndown по приоритетам", func(t *testing.T) {
+		w := get("/analytics/burndown?from=2025-03-01&to=2025-03-14&priority=high,medium")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []domain.Priority{domain.PriorityHigh, domain.PriorityMedium}, uc.priorities)
+		assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), uc.period.To)
+	})
+
+	invalid := []struct {
+		url   string
+		field string
+	}{
+		{"/analytics?period=1y", "period"},
+		{"/analytics?period=7d&from=2025-01-01", "period"},
+		{"/analytics?to=2025-01-01", "from"},
+		{"/analytics?from=вчера", "from"},
+		{"/analytics?from=2025-02-01&to=2025-01-01", "from"},
+		{"/analytics?from=2023-01-01&to=2025-01-01", "from"},
+		{"/analytics/timeseries?bucket=month", "bucket"},
+		{"/analytics/burndown?priority=urgent", "priority"},
+		{"/analytics/burndown?period=2w", "period"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.url, func(t *testing.T) {
+			w := get(tt.url)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+
+			var problem middleware.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, domain.CodeValidation, problem.Code)
+			assert.Equal(t, tt.field, problem.Field)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	"GoTasker/pkg/utils"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "test-secret"
+
+// fakeRevocationChecker хранит отозванные jti в памяти
+type fakeRevocationChecker struct {
+	revoked map[string]bool
+}
+
+func (f *fakeRevocationChecker) IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error) {
+	return f.revoked[tokenID], nil
+}
+
+func setupAuthRouter(checker *fakeRevocationChecker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	authMiddleware := middleware.NewAuthMiddleware(testJWTSecret, checker)
+
+	router.GET("/protected", authMiddleware.RequireAuth, func(c *gin.Context) {
+		user, ok := domain.UserFromContext(c.Request.Context())
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "пользователь не найден в контексте"})
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	})
+
+	return router
+}
+
+func doProtectedRequest(router *gin.Engine, authHeader string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	checker := &fakeRevocationChecker{revoked: map[string]bool{}}
+	router := setupAuthRouter(checker)
+
+	t.Run("валидный access токен", func(t *testing.T) {
+		token, err := utils.GenerateAccessToken(42, "john", "john@example.com", "session", testJWTSecret, time.Minute)
+		require.NoError(t, err)
+
+		w := doProtectedRequest(router, "Bearer "+token)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":42`)
+	})
+
+	t.Run("отсутствует заголовок", func(t *testing.T) {
+		w := doProtectedRequest(router, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("неверная схема авторизации", func(t *testing.T) {
+		w := doProtectedRequest(router, "Basic am9objpwYXNz")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("испорченный токен", func(t *testing.T) {
+		w := doProtectedRequest(router, "Bearer not-a-jwt")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("токен подписан другим ключом", func(t *testing.T) {
+		token, err := utils.GenerateAccessToken(42, "john", "john@example.com", "session", "other-secret", time.Minute)
+		require.NoError(t, err)
+
+		w := doProtectedRequest(router, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("просроченный токен", func(t *testing.T) {
+		token, err := utils.GenerateAccessToken(42, "john", "john@example.com", "session", testJWTSecret, -time.Minute)
+		require.NoError(t, err)
+
+		w := doProtectedRequest(router, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "срок действия токена истёк")
+	})
+
+	t.Run("refresh токен вместо access", func(t *testing.T) {
+		token, err := utils.GenerateRefreshToken(42, "john@example.com", "family", "jti", testJWTSecret, time.Hour)
+		require.NoError(t, err)
+
+		w := doProtectedRequest(router, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("отозванный токен", func(t *testing.T) {
+		token, err := utils.GenerateAccessToken(42, "john", "john@example.com", "session", testJWTSecret, time.Minute)
+		require.NoError(t, err)
+
+		claims, err := utils.ParseAccessToken(token, []byte(testJWTSecret))
+		require.NoError(t, err)
+		checker.revoked[claims.ID] = true
+
+		w := doProtectedRequest(router, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "токен отозван")
+	})
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"валидация", domain.NewFieldError("title", "название не может быть пустым"), http.StatusBadRequest, domain.CodeValidation},
+		{"задача не найдена", domain.NewTaskNotFoundError(5), http.StatusNotFound, domain.CodeTaskNotFound},
+		{"email занят", domain.ErrEmailTaken, http.StatusConflict, domain.CodeEmailTaken},
+		{"неверные учётные данные", domain.ErrInvalidCredentials, http.StatusUnauthorized, domain.CodeInvalidCredentials},
+		{"deny-list недоступен", domain.ErrTokenCheckUnavailable, http.StatusServiceUnavailable, domain.CodeTokenCheckUnavailable},
+		{"нет If-Match", domain.NewError(domain.ErrValidation, domain.CodeIfMatchRequired, "нужен If-Match"), http.StatusPreconditionRequired, domain.CodeIfMatchRequired},
+		{"конфликт версий", &domain.VersionConflictError{Current: &domain.Task{ID: 1, Version: 3}}, http.StatusPreconditionFailed, domain.CodeVersionMismatch},
+		{"внутренняя ошибка", errors.New("pq: connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := middleware.NewProblem(tt.err, i18n.RU)
+
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, http.StatusText(tt.status), problem.Title)
+		})
+	}
+
+	t.Run("текст внутренней ошибки не раскрывается", func(t *testing.T) {
+		problem := middleware.NewProblem(errors.New("pq: connection refused"), i18n.EN)
+		assert.NotContains(t, problem.Detail, "pq")
+		assert.Equal(t, "Internal server error. Please try again later.", problem.Detail)
+	})
+}
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		_ = c.Error(domain.NewTaskNotFoundError(7))
+	})
+	router.GET("/ok", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+		_ = c.Error(errors.New("ошибка после ответа"))
+	})
+
+	t.Run("ответ problem+json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/7", nil))
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+
+		var problem middleware.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, domain.CodeTaskNotFound, problem.Code)
+		assert.Equal(t, "задача с id 7 не найдена", problem.Detail)
+		assert.Equal(t, "/tasks/7", problem.Instance)
+	})
+
+	t.Run("уже отправленный ответ не переписывается", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", w.Body.String())
+	})
+}
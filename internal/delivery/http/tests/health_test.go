@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"GoTasker/internal/domain"
+	"GoTasker/internal/handler/health"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeReadiness возвращает заранее заданный результат проверки
+type fakeReadiness struct {
+	readiness *domain.Readiness
+}
+
+func (f *fakeReadiness) Readiness(ctx context.Context) *domain.Readiness {
+	return f.readiness
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(status domain.HealthStatus, path string) *httptest.ResponseRecorder {
+		handler := health.NewHealthHandler(&fakeReadiness{readiness: &domain.Readiness{
+			Status: status,
+			Checks: map[string]*domain.DependencyCheck{"redis": {Status: status}},
+		}})
+
+		router := gin.New()
+		router.GET("/healthz", handler.Liveness)
+		router.GET("/readyz", handler.Readiness)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("liveness не зависит от зависимостей", func(t *testing.T) {
+		w := request(domain.HealthFail, "/healthz")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	})
+
+	tests := []struct {
+		status domain.HealthStatus
+		code   int
+	}{
+		{domain.HealthOK, http.StatusOK},
+		{domain.HealthFail, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run("readiness "+string(tt.status), func(t *testing.T) {
+			w := request(tt.status, "/readyz")
+			require.Equal(t, tt.code, w.Code)
+
+			var body domain.Readiness
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.status, body.Status)
+			assert.Equal(t, tt.status, body.Checks["redis"].Status)
+		})
+	}
+}
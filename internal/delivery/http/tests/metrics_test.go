@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics_HTTPRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.Metrics())
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/metrics-test/:id", func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+
+	for _, url := range []string{"/metrics-test/1", "/metrics-test/2", "/no-such-route"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `gotasker_http_requests_total{method="GET",route="/metrics-test/:id",status="418"} 2`,
+		"запросы учитываются по шаблону маршрута, а не по пути")
+	assert.Contains(t, body, `gotasker_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `gotasker_http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="418"} 2`)
+}
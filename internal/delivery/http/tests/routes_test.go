@@ -0,0 +1,115 @@
+package tests
+
+import (
+	deliveryhttp "GoTasker/internal/delivery/http"
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/handler/admin"
+	"GoTasker/internal/handler/analytics"
+	"GoTasker/internal/handler/auth"
+	"GoTasker/internal/handler/health"
+	"GoTasker/internal/handler/tasks"
+	"GoTasker/internal/i18n"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetupRoutes_ProtectedWithoutToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	require.NotPanics(t, func() {
+		deliveryhttp.SetupRoutes(router,
+			tasks.NewTaskHandler(nil),
+			analytics.NewAnalyticsHandler(nil),
+			auth.NewUserAuthHandler(nil),
+			admin.NewAdminHandler(nil),
+			health.NewHealthHandler(nil),
+			middleware.NewAuthMiddleware(testJWTSecret, &fakeRevocationChecker{}),
+			middleware.NewAdminMiddleware(nil),
+			middleware.NewLocaleMiddleware(i18n.RU),
+		)
+	})
+
+	protected := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/tasks"},
+		{http.MethodGet, "/tasks/1"},
+		{http.MethodGet, "/tasks/1/history"},
+		{http.MethodGet, "/tasks/trash"},
+		{http.MethodPost, "/tasks/1/restore"},
+		{http.MethodGet, "/tasks/export"},
+		{http.MethodGet, "/tasks/search?q=отчёт"},
+		{http.MethodPut, "/tasks/1"},
+		{http.MethodDelete, "/tasks/1"},
+		{http.MethodDelete, "/tasks/1?permanent=true"},
+		{http.MethodGet, "/analytics"},
+		{http.MethodGet, "/analytics/timeseries"},
+		{http.MethodGet, "/analytics/burndown"},
+		{http.MethodPost, "/auth/logout"},
+		{http.MethodGet, "/admin/jobs"},
+	}
+
+	for _, route := range protected {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(route.method, route.path, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+func TestSetupRoutes_AcceptLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	deliveryhttp.SetupRoutes(router,
+		tasks.NewTaskHandler(nil),
+		analytics.NewAnalyticsHandler(nil),
+		auth.NewUserAuthHandler(nil),
+		admin.NewAdminHandler(nil),
+		health.NewHealthHandler(nil),
+		middleware.NewAuthMiddleware(testJWTSecret, &fakeRevocationChecker{}),
+		middleware.NewAdminMiddleware(nil),
+		middleware.NewLocaleMiddleware(i18n.RU),
+	)
+
+	tests := []struct {
+		acceptLanguage string
+		lang           string
+		detail         string
+	}{
+		{"", "ru", "отсутствует токен авторизации"},
+		{"en-US,en;q=0.9", "en", "authorization token is missing"},
+		{"de, ru;q=0.5", "ru", "отсутствует токен авторизации"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.lang, w.Header().Get("Content-Language"))
+
+			var problem middleware.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.detail, problem.Detail)
+			assert.Equal(t, "token_missing", problem.Code)
+		})
+	}
+}
@@ -23,21 +23,41 @@ func (m *MockTaskRepo) Create(ctx context.Context, task *domain.Task) error {
 	return nil
 }
 
-func (m *MockTaskRepo) Update(ctx context.Context, updates map[string]interface{}) error {
-	if id, ok := updates["id"].(int64); ok && id == 1 {
+func (m *MockTaskRepo) Update(ctx context.Context, ownerID, id, version int64, patch *domain.TaskPatch, check func(current *domain.Task) error) (*domain.Task, error) {
+	if id == 1 {
+		current := &domain.Task{ID: 1, OwnerID: ownerID, Status: domain.StatusPending, Version: 1}
+		if version != 0 && version != current.Version {
+			return nil, &domain.VersionConflictError{Current: current}
+		}
+		if check != nil {
+			if err := check(current); err != nil {
+				return nil, err
+			}
+		}
+		return &domain.Task{ID: 1, OwnerID: ownerID, Version: 2}, nil
+	}
+	return nil, domain.NewTaskNotFoundError(id)
+}
+
+func (m *MockTaskRepo) Delete(ctx context.Context, ownerID, id int64) error {
+	if id == 1 {
 		return nil
 	}
 	return nil
 }
 
-func (m *MockTaskRepo) Delete(ctx context.Context, id int64) error {
+func (m *MockTaskRepo) Restore(ctx context.Context, ownerID, id int64) (*domain.Task, error) {
 	if id == 1 {
-		return nil
+		return &domain.Task{ID: 1, OwnerID: ownerID, Version: 2}, nil
 	}
+	return nil, domain.NewTaskNotFoundError(id)
+}
+
+func (m *MockTaskRepo) Purge(ctx context.Context, ownerID, id int64) error {
 	return nil
 }
 
-func (m *MockTaskRepo) GetByID(ctx context.Context, id int64) (*domain.Task, error) {
+func (m *MockTaskRepo) GetByID(ctx context.Context, ownerID, id int64) (*domain.Task, error) {
 	if id == 1 {
 		return &domain.Task{
 			ID:          1,
@@ -46,24 +66,32 @@ func (m *MockTaskRepo) GetByID(ctx context.Context, id int64) (*domain.Task, err
 			Status:      domain.StatusPending,
 			Priority:    domain.PriorityHigh,
 			DueDate:     time.Now().Add(24 * time.Hour),
+			Version:     1,
 		}, nil
 	}
-	return nil, nil
+	return nil, domain.NewTaskNotFoundError(id)
 }
 
-func (m *MockTaskRepo) GetAll(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error) {
-	return []*domain.Task{
-		{
-			ID:          1,
-			Title:       "Test Task",
-			Description: "Test Description",
-			Status:      domain.StatusPending,
-			Priority:    domain.PriorityHigh,
-			DueDate:     time.Now().Add(24 * time.Hour),
+func (m *MockTaskRepo) GetAll(ctx context.Context, ownerID int64, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error) {
+	return &domain.TaskPage{
+		Items: []*domain.Task{
+			{
+				ID:          1,
+				Title:       "Test Task",
+				Description: "Test Description",
+				Status:      domain.StatusPending,
+				Priority:    domain.PriorityHigh,
+				DueDate:     time.Now().Add(24 * time.Hour),
+			},
 		},
+		Total: 1,
 	}, nil
 }
 
+func (m *MockTaskRepo) Search(ctx context.Context, ownerID int64, query string, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskSearchPage, error) {
+	return &domain.TaskSearchPage{Items: []*domain.TaskSearchResult{}}, nil
+}
+
 func (m *MockTaskRepo) CreateTask(task *domain.Task) (*domain.Task, error) {
 	task.ID = 1
 	return task, nil
@@ -128,6 +156,18 @@ func (m *MockTaskRepo) ImportTasks(ctx context.Context, tasks []*domain.Task) (i
 	return len(tasks), nil
 }
 
+func (m *MockTaskRepo) History(ctx context.Context, ownerID, taskID int64) ([]*domain.TaskEvent, error) {
+	return []*domain.TaskEvent{}, nil
+}
+
+func (m *MockTaskRepo) DeleteExpiredTasks(ctx context.Context, overdueFor time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockTaskRepo) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, nil
+}
+
 // TestServer структура с роутером и юзкейсом
 type TestServer struct {
 	router      *gin.Engine
@@ -142,7 +182,7 @@ func setupTestServer(t *testing.T) *TestServer {
 
 	// Создаем мок-репозиторий и useCase
 	taskRepo := &MockTaskRepo{}
-	taskUseCase := tasks.NewTaskUseCase(taskRepo)
+	taskUseCase := tasks.NewTaskUseCase(taskRepo, domain.DefaultWorkflow(), nil)
 
 	// Регистрация маршрутов для задач
 	router.POST("/api/v1/tasks", func(c *gin.Context) {
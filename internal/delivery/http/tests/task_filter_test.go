@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	handler "GoTasker/internal/handler/tasks"
+	"GoTasker/internal/useCase/tasks"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// filterRecordingRepo запоминает фильтр, с которым был вызван GetAll
+type filterRecordingRepo struct {
+	MockTaskRepo
+	filter *domain.TaskFilter
+}
+
+func (r *filterRecordingRepo) GetAll(ctx context.Context, ownerID int64, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error) {
+	r.filter = filter
+	return &domain.TaskPage{Items: []*domain.Task{}}, nil
+}
+
+func setupFilterRouter(repo *filterRecordingRepo) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	taskHandler := handler.NewTaskHandler(tasks.NewTaskUseCase(repo, domain.DefaultWorkflow(), nil))
+
+	router.GET("/tasks", withTestUser, taskHandler.GetAll)
+
+	return router
+}
+
+// withTestUser подставляет аутентифицированного пользователя вместо проверки токена
+func withTestUser(c *gin.Context) {
+	ctx := domain.ContextWithUser(c.Request.Context(), &domain.AuthUser{ID: 1})
+	c.Request = c.Request.WithContext(ctx)
+}
+
+func TestTaskAPI_GetTasksFilter(t *testing.T) {
+	repo := &filterRecordingRepo{}
+	router := setupFilterRouter(repo)
+
+	t.Run("списки и диапазоны дат", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet,
+			"/tasks?status=pending,in_progress&priority=high&due_after=2025-01-01&due_before=2025-02-01T12:00:00Z&overdue=true&description=отчёт", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []domain.Status{domain.StatusPending, domain.StatusInProgress}, repo.filter.Statuses)
+		assert.Equal(t, []domain.Priority{domain.PriorityHigh}, repo.filter.Priorities)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *repo.filter.DueAfter)
+		assert.Equal(t, time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC), *repo.filter.DueBefore)
+		assert.True(t, repo.filter.Overdue)
+		assert.Equal(t, "отчёт", repo.filter.Description)
+	})
+
+	invalid := []struct {
+		query string
+		field string
+	}{
+		{"status=pending,archived", "status"},
+		{"priority=urgent", "priority"},
+		{"due_before=завтра", "due_before"},
+		{"created_after=2025-13-01", "created_after"},
+		{"due_after=2025-02-01&due_before=2025-01-01", "due_after"},
+		{"overdue=yes", "overdue"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/tasks?"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem middleware.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, domain.CodeValidation, problem.Code)
+			assert.Equal(t, tt.field, problem.Field)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"GoTasker/internal/delivery/http/middleware"
+	"GoTasker/internal/domain"
+	handler "GoTasker/internal/handler/tasks"
+	"GoTasker/internal/useCase/tasks"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTaskAPI_UpdateIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	taskHandler := handler.NewTaskHandler(tasks.NewTaskUseCase(&MockTaskRepo{}, domain.DefaultWorkflow(), nil))
+	router.PUT("/tasks/:id", withTestUser, taskHandler.Update)
+
+	doUpdate := func(ifMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := `{"title":"Новое название","status":"pending","priority":"low","due_date":"2025-05-01T00:00:00Z"}`
+		req := httptest.NewRequest(http.MethodPut, "/tasks/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("актуальная версия", func(t *testing.T) {
+		w := doUpdate(`"1"`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("устаревшая версия", func(t *testing.T) {
+		w := doUpdate(`"5"`)
+
+		require.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+		var body struct {
+			Current struct {
+				Version int64 `json:"version"`
+			} `json:"current"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, int64(1), body.Current.Version)
+	})
+
+	t.Run("без If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionRequired, doUpdate("").Code)
+	})
+
+	t.Run("невалидный If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, doUpdate("abc").Code)
+	})
+
+	t.Run("слабый ETag не подходит для If-Match", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, doUpdate(`W/"1"`).Code)
+	})
+}
+
+func TestTaskAPI_Patch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	taskHandler := handler.NewTaskHandler(tasks.NewTaskUseCase(&MockTaskRepo{}, domain.DefaultWorkflow(), nil))
+	router.PATCH("/tasks/:id", withTestUser, taskHandler.Patch)
+
+	doPatch := func(contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("If-Match", `"1"`)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("merge patch", func(t *testing.T) {
+		w := doPatch("application/merge-patch+json", `{"description":null}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("очистка обязательного поля", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, doPatch("application/json", `{"due_date":null}`).Code)
+	})
+
+	t.Run("не JSON объект", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, doPatch("application/json", `[1, 2]`).Code)
+	})
+
+	t.Run("неподдерживаемый Content-Type", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnsupportedMediaType, doPatch("text/plain", `{}`).Code)
+	})
+}
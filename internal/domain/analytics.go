@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"time"
+)
+
+// MaxAnalyticsDays максимальная длина периода аналитики: ограничивает число точек временного ряда
+const MaxAnalyticsDays = 366
+
+// DefaultAnalyticsPeriod период аналитики, если не переданы ни period, ни from/to
+const DefaultAnalyticsPeriod = "7d"
+
+// analyticsPeriods допустимые значения параметра period
+var analyticsPeriods = map[string]int{
+	"7d":  7,
+	"30d": 30,
+	"90d": 90,
+}
+
+// AnalyticsPeriod полуоткрытый интервал [From, To), за который считается аналитика
+type AnalyticsPeriod struct {
+	From time.Time
+	To   time.Time
+	key  string
+}
+
+// AnalyticsPeriodParams сырые значения query-параметров периода
+type AnalyticsPeriodParams struct {
+	Period string
+	From   string
+	To     string
+}
+
+// ParseAnalyticsPeriod разбирает period=7d|30d|90d или from/to относительно now.
+// from и to принимают дату YYYY-MM-DD или RFC3339; дата в to включается в период целиком, без to период длится до now
+func ParseAnalyticsPeriod(p AnalyticsPeriodParams, now time.Time) (*AnalyticsPeriod, error) {
+	if p.Period != "" && (p.From != "" || p.To != "") {
+		return nil, NewFieldError("period", i18n.AnalyticsPeriodConflict)
+	}
+
+	if p.From == "" && p.To == "" {
+		name := p.Period
+		if name == "" {
+			name = DefaultAnalyticsPeriod
+		}
+		days, ok := analyticsPeriods[name]
+		if !ok {
+			return nil, newFilterError("period", name, i18n.FilterInvalidValue, "7d, 30d, 90d")
+		}
+		return &AnalyticsPeriod{From: now.AddDate(0, 0, -days), To: now, key: "period=" + name}, nil
+	}
+
+	if p.From == "" {
+		return nil, NewFieldError("from", i18n.AnalyticsFromRequired)
+	}
+	from, err := parseFilterTime(p.From)
+	if err != nil {
+		return nil, newFilterError("from", p.From, i18n.FilterInvalidDate)
+	}
+
+	to := now
+	if p.To != "" {
+		if to, err = time.Parse(time.DateOnly, p.To); err == nil {
+			to = to.AddDate(0, 0, 1)
+		} else if to, err = time.Parse(time.RFC3339, p.To); err != nil {
+			return nil, newFilterError("to", p.To, i18n.FilterInvalidDate)
+		}
+	}
+
+	if !from.Before(to) {
+		return nil, newFilterError("from", p.From, i18n.FilterInvalidRange, "to")
+	}
+	if to.Sub(from) > MaxAnalyticsDays*24*time.Hour {
+		return nil, NewFieldError("from", i18n.AnalyticsPeriodTooLong, MaxAnalyticsDays)
+	}
+
+	return &AnalyticsPeriod{From: from, To: to, key: "from=" + p.From + "&to=" + p.To}, nil
+}
+
+// CacheKey значение периода для ключа кэша. Для относительных периодов это их имя,
+// чтобы кэш не промахивался из-за того, что now меняется с каждым запросом
+func (p *AnalyticsPeriod) CacheKey() string {
+	return p.key
+}
+
+// TimeBucket размер интервала временного ряда
+type TimeBucket string
+
+const (
+	BucketDay  TimeBucket = "day"
+	BucketWeek TimeBucket = "week" // Недели начинаются с понедельника
+)
+
+// ParseTimeBucket разбирает параметр bucket; пустое значение — по дням
+func ParseTimeBucket(value string) (TimeBucket, error) {
+	switch TimeBucket(value) {
+	case "":
+		return BucketDay, nil
+	case BucketDay, BucketWeek:
+		return TimeBucket(value), nil
+	}
+	return "", newFilterError("bucket", value, i18n.FilterInvalidValue, "day, week")
+}
+
+// TimeSeriesPoint счётчики задач за один интервал, начинающийся в Start
+type TimeSeriesPoint struct {
+	Start     time.Time `json:"start"`
+	Created   int       `json:"created"`   // Создано задач
+	Completed int       `json:"completed"` // Выполнено задач
+	Overdue   int       `json:"overdue"`   // Задач, срок которых истёк в интервале, а выполнены они не были или были выполнены позже срока
+}
+
+// TimeSeries временной ряд для графиков
+type TimeSeries struct {
+	From   time.Time         `json:"from"`
+	To     time.Time         `json:"to"`
+	Bucket TimeBucket        `json:"bucket"`
+	Points []TimeSeriesPoint `json:"points"`
+}
+
+// BurndownPoint состояние набора задач на конец дня Date по ежедневному снимку.
+// Remaining и Completed равны nil, если снимка за этот день нет
+type BurndownPoint struct {
+	Date      time.Time `json:"date"`
+	Remaining *int      `json:"remaining"` // Задач не в статусе done
+	Completed *int      `json:"completed"` // Задач в статусе done
+	Ideal     *float64  `json:"ideal"`     // Идеальный остаток: равномерное сгорание от первого снимка периода до нуля в последний день
+}
+
+// Burndown данные для графиков burndown и burnup
+type Burndown struct {
+	From       time.Time       `json:"from"`
+	To         time.Time       `json:"to"`
+	Priorities []Priority      `json:"priorities,omitempty"` // Фильтр по приоритету; пусто — все задачи
+	Points     []BurndownPoint `json:"points"`
+}
+
+// ProjectIdeal заполняет Ideal: от остатка первого дня со снимком линейно до нуля в последний день периода.
+// До первого снимка идеальная линия не строится
+func (b *Burndown) ProjectIdeal() {
+	start := -1
+	for i, p := range b.Points {
+		if p.Remaining != nil {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return
+	}
+
+	initial := float64(*b.Points[start].Remaining)
+	last := len(b.Points) - 1
+	for i := start; i <= last; i++ {
+		ideal := initial
+		if last > start {
+			ideal = initial * float64(last-i) / float64(last-start)
+		}
+		b.Points[i].Ideal = &ideal
+	}
+}
+
+// TaskCount количество задач с одним статусом и приоритетом
+type TaskCount struct {
+	Status   Status
+	Priority Priority
+	Count    int
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"context"
+	"time"
+)
+
+var (
+	// ErrUnauthenticated возвращается, если в контексте нет аутентифицированного пользователя
+	ErrUnauthenticated = NewError(ErrUnauthorized, CodeUnauthenticated, i18n.Unauthenticated)
+	// ErrInvalidCredentials возвращается при входе с неизвестным email или неверным паролем
+	ErrInvalidCredentials = NewError(ErrUnauthorized, CodeInvalidCredentials, i18n.InvalidCredentials)
+	// ErrEmailTaken возвращается при регистрации с уже занятым email
+	ErrEmailTaken = NewError(ErrConflict, CodeEmailTaken, i18n.EmailTaken)
+	// ErrUserNotFound возвращается, если пользователя с указанным email нет
+	ErrUserNotFound = NewError(ErrNotFound, CodeUserNotFound, i18n.UserNotFound)
+
+	// ErrTokenMissing возвращается, если запрос не содержит access токен
+	ErrTokenMissing = NewError(ErrUnauthorized, CodeTokenMissing, i18n.TokenMissing)
+	// ErrInvalidToken возвращается для испорченного токена или заголовка Authorization неверного формата
+	ErrInvalidToken = NewError(ErrUnauthorized, CodeInvalidToken, i18n.InvalidToken)
+	// ErrTokenExpired возвращается для access токена с истёкшим сроком действия
+	ErrTokenExpired = NewError(ErrUnauthorized, CodeTokenExpired, i18n.TokenExpired)
+	// ErrWrongTokenType возвращается, если вместо access токена предъявлен refresh токен
+	ErrWrongTokenType = NewError(ErrUnauthorized, CodeWrongTokenType, i18n.WrongTokenType)
+	// ErrTokenRevoked возвращается для токена, отозванного выходом из системы
+	ErrTokenRevoked = NewError(ErrUnauthorized, CodeTokenRevoked, i18n.TokenRevoked)
+	// ErrTokenCheckUnavailable возвращается, если хранилище отозванных токенов недоступно
+	ErrTokenCheckUnavailable = NewError(ErrUnavailable, CodeTokenCheckUnavailable, i18n.TokenCheckUnavailable)
+
+	// ErrInvalidRefreshToken возвращается для испорченного, просроченного или чужого refresh токена
+	ErrInvalidRefreshToken = NewError(ErrUnauthorized, CodeInvalidRefreshToken, i18n.InvalidRefreshToken)
+	// ErrSessionRevoked возвращается, если семейство refresh токенов отозвано или истекло
+	ErrSessionRevoked = NewError(ErrUnauthorized, CodeSessionRevoked, i18n.SessionRevoked)
+	// ErrRefreshTokenReused возвращается при повторном предъявлении уже использованного refresh токена
+	ErrRefreshTokenReused = NewError(ErrUnauthorized, CodeRefreshTokenReused, i18n.RefreshTokenReused)
+
+	// ErrAdminRequired возвращается, если эндпоинт доступен только администраторам
+	ErrAdminRequired = NewError(ErrForbidden, CodeAdminRequired, i18n.AdminRequired)
+)
+
+type authUserKey struct{}
+
+// AuthUser представляет аутентифицированного пользователя текущего запроса
+type AuthUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+
+	TokenID        string    `json:"-"` // jti access токена текущего запроса
+	SessionID      string    `json:"-"` // Сессия, в которой выпущен токен
+	TokenExpiresAt time.Time `json:"-"` // Время истечения access токена
+}
+
+// ContextWithUser возвращает контекст с аутентифицированным пользователем
+func ContextWithUser(ctx context.Context, user *AuthUser) context.Context {
+	return context.WithValue(ctx, authUserKey{}, user)
+}
+
+// UserFromContext извлекает аутентифицированного пользователя из контекста
+func UserFromContext(ctx context.Context) (*AuthUser, bool) {
+	user, ok := ctx.Value(authUserKey{}).(*AuthUser)
+	return user, ok && user != nil
+}
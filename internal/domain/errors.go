@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"errors"
+)
+
+// Категории ошибок. По категории транспортный слой выбирает код ответа,
+// а конкретная ошибка уточняет её стабильным машиночитаемым кодом
+var (
+	ErrNotFound     = errors.New("не найдено")
+	ErrValidation   = errors.New("ошибка валидации")
+	ErrConflict     = errors.New("конфликт")
+	ErrUnauthorized = errors.New("не авторизован")
+	ErrForbidden    = errors.New("доступ запрещён")
+	ErrUnavailable  = errors.New("сервис временно недоступен")
+)
+
+// Машиночитаемые коды ошибок. Коды — часть API: клиенты опираются на них вместо текста сообщения
+const (
+	CodeValidation           = "validation_failed"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeInvalidSearchQuery   = "invalid_search_query"
+	CodeIfMatchRequired      = "if_match_required"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeImportNoValidTasks   = "import_no_valid_tasks"
+
+	CodeTaskNotFound      = "task_not_found"
+	CodeVersionMismatch   = "version_mismatch"
+	CodeInvalidTransition = "invalid_status_transition"
+	CodeEmailTaken        = "email_taken"
+	CodeUserNotFound      = "user_not_found"
+
+	CodeUnauthenticated       = "unauthenticated"
+	CodeInvalidCredentials    = "invalid_credentials"
+	CodeTokenMissing          = "token_missing"
+	CodeInvalidToken          = "invalid_token"
+	CodeTokenExpired          = "token_expired"
+	CodeWrongTokenType        = "wrong_token_type"
+	CodeTokenRevoked          = "token_revoked"
+	CodeInvalidRefreshToken   = "invalid_refresh_token"
+	CodeSessionRevoked        = "session_revoked"
+	CodeRefreshTokenReused    = "refresh_token_reused"
+	CodeTokenCheckUnavailable = "token_check_unavailable"
+	CodeAdminRequired         = "admin_required"
+)
+
+// Error доменная ошибка: категория, стабильный код и сообщение для клиента
+type Error struct {
+	Kind    error         // Одна из категорий: ErrNotFound, ErrValidation, ...
+	Code    string        // Машиночитаемый код
+	Key     i18n.Key      // Ключ сообщения в каталоге i18n
+	Args    []interface{} // Аргументы сообщения
+	Field   string        // Поле или параметр запроса, к которому относится ошибка валидации
+	Details []string      // Дополнительные подробности, например список отклонённых записей
+}
+
+// Error возвращает сообщение на языке каталога по умолчанию; используется в логах
+func (e *Error) Error() string {
+	return e.Localize(i18n.Fallback)
+}
+
+// Localize возвращает сообщение для клиента на языке lang
+func (e *Error) Localize(lang i18n.Lang) string {
+	return i18n.T(lang, e.Key, e.Args...)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Is сравнивает доменные ошибки по коду, поэтому errors.Is(err, ErrTaskNotFound)
+// срабатывает и для ошибки с конкретным id в сообщении
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// NewError создаёт доменную ошибку с сообщением key из каталога i18n
+func NewError(kind error, code string, key i18n.Key, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Key: key, Args: args}
+}
+
+// NewValidationError создаёт ошибку валидации
+func NewValidationError(key i18n.Key, args ...interface{}) *Error {
+	return NewError(ErrValidation, CodeValidation, key, args...)
+}
+
+// NewFieldError создаёт ошибку валидации конкретного поля или параметра запроса
+func NewFieldError(field string, key i18n.Key, args ...interface{}) *Error {
+	err := NewValidationError(key, args...)
+	err.Field = field
+	return err
+}
+
+// ErrTaskNotFound задача не найдена или принадлежит другому пользователю
+var ErrTaskNotFound = NewError(ErrNotFound, CodeTaskNotFound, i18n.TaskNotFound)
+
+// NewTaskNotFoundError ошибка ErrTaskNotFound с id задачи в сообщении
+func NewTaskNotFoundError(id int64) *Error {
+	return NewError(ErrNotFound, CodeTaskNotFound, i18n.TaskNotFoundID, id)
+}
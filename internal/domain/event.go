@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"time"
+)
+
+// TaskEventType тип события в истории задачи
+type TaskEventType string
+
+const (
+	TaskEventCreated       TaskEventType = "created"        // Задача создана
+	TaskEventUpdated       TaskEventType = "updated"        // Изменены поля задачи
+	TaskEventStatusChanged TaskEventType = "status_changed" // Изменён статус (и, возможно, другие поля)
+	TaskEventTrashed       TaskEventType = "trashed"        // Задача перемещена в корзину пользователем или очисткой просроченных
+	TaskEventRestored      TaskEventType = "restored"       // Задача восстановлена из корзины
+	TaskEventDeleted       TaskEventType = "deleted"        // Задача удалена безвозвратно
+	TaskEventImported      TaskEventType = "imported"       // Задача создана импортом
+)
+
+// FieldChange значение поля до и после изменения; nil — поля не было (создание или удаление)
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// TaskEvent запись в истории задачи
+type TaskEvent struct {
+	ID        int64                  `json:"id"`
+	TaskID    int64                  `json:"task_id"`
+	OwnerID   int64                  `json:"-"`
+	ActorID   *int64                 `json:"actor_id"` // Кто выполнил действие; nil — система (фоновая очистка)
+	Type      TaskEventType          `json:"type"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NewTaskEvent создаёт событие по состоянию задачи до и после действия.
+// before == nil — задача создана, after == nil — удалена. Если поля не изменились, возвращает nil
+func NewTaskEvent(eventType TaskEventType, actorID *int64, before, after *Task) *TaskEvent {
+	changes := DiffTasks(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	task := after
+	if task == nil {
+		task = before
+	}
+
+	if eventType == TaskEventUpdated {
+		if _, ok := changes["status"]; ok {
+			eventType = TaskEventStatusChanged
+		}
+	}
+
+	return &TaskEvent{
+		TaskID:  task.ID,
+		OwnerID: task.OwnerID,
+		ActorID: actorID,
+		Type:    eventType,
+		Changes: changes,
+	}
+}
+
+// DiffTasks сравнивает изменяемые поля двух состояний задачи; nil означает отсутствие задачи
+func DiffTasks(before, after *Task) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+
+	field := func(name string, get func(t *Task) interface{}) {
+		var b, a interface{}
+		if before != nil {
+			b = get(before)
+		}
+		if after != nil {
+			a = get(after)
+		}
+		if b != a {
+			changes[name] = FieldChange{Before: b, After: a}
+		}
+	}
+
+	field("title", func(t *Task) interface{} { return t.Title })
+	field("description", func(t *Task) interface{} { return t.Description })
+	field("status", func(t *Task) interface{} { return string(t.Status) })
+	field("priority", func(t *Task) interface{} { return string(t.Priority) })
+	field("due_date", func(t *Task) interface{} { return t.DueDate.UTC().Format(time.RFC3339) })
+	field("deleted_at", func(t *Task) interface{} {
+		if t.DeletedAt == nil {
+			return nil
+		}
+		return t.DeletedAt.UTC().Format(time.RFC3339)
+	})
+
+	return changes
+}
+
+// TaskChange уведомление об изменении задач для подписчиков (например, кэша аналитики)
+type TaskChange struct {
+	OwnerID int64         // Владелец изменённых задач; 0 — задачи разных пользователей (фоновая очистка)
+	Type    TaskEventType // Что произошло с задачами
+}
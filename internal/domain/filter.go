@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newFilterError ошибка валидации query-параметра фильтра; первые аргументы сообщения — имя и значение параметра
+func newFilterError(field, value string, key i18n.Key, args ...interface{}) *Error {
+	return NewFieldError(field, key, append([]interface{}{field, value}, args...)...)
+}
+
+// TaskFilter структура для фильтрации задач
+type TaskFilter struct {
+	Statuses      []Status   `json:"statuses,omitempty"`       // Любой из статусов
+	Priorities    []Priority `json:"priorities,omitempty"`     // Любой из приоритетов
+	DueDate       *time.Time `json:"due_date,omitempty"`       // Срок в указанный календарный день
+	DueBefore     *time.Time `json:"due_before,omitempty"`     // Срок строго раньше
+	DueAfter      *time.Time `json:"due_after,omitempty"`      // Срок строго позже
+	CreatedBefore *time.Time `json:"created_before,omitempty"` // Создана строго раньше
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`  // Создана строго позже
+	Overdue       bool       `json:"overdue,omitempty"`        // Срок прошёл, а задача не выполнена
+	Title         string     `json:"title,omitempty"`          // Подстрока в названии
+	Description   string     `json:"description,omitempty"`    // Подстрока в описании
+	InTrash       bool       `json:"-"`                        // Задачи из корзины вместо активных; задаётся только обработчиком корзины
+}
+
+// TaskFilterParams сырые значения query-параметров фильтра
+type TaskFilterParams struct {
+	Status        string
+	Priority      string
+	DueDate       string
+	DueBefore     string
+	DueAfter      string
+	CreatedBefore string
+	CreatedAfter  string
+	Overdue       string
+	Title         string
+	Description   string
+}
+
+// ParseTaskFilter проверяет параметры фильтра и возвращает ошибку валидации для первого некорректного
+func ParseTaskFilter(p TaskFilterParams) (*TaskFilter, error) {
+	filter := &TaskFilter{
+		Title:       p.Title,
+		Description: p.Description,
+	}
+
+	// Набор статусов задаётся конфигурацией, поэтому их проверяет use case (ValidateStatuses)
+	for _, s := range splitList(p.Status) {
+		filter.Statuses = append(filter.Statuses, Status(s))
+	}
+
+	for _, s := range splitList(p.Priority) {
+		if !Priority(s).IsValid() {
+			return nil, newFilterError("priority", s, i18n.FilterInvalidValue, "low, medium, high")
+		}
+		filter.Priorities = append(filter.Priorities, Priority(s))
+	}
+
+	dates := []struct {
+		field string
+		value string
+		dest  **time.Time
+	}{
+		{"due_date", p.DueDate, &filter.DueDate},
+		{"due_before", p.DueBefore, &filter.DueBefore},
+		{"due_after", p.DueAfter, &filter.DueAfter},
+		{"created_before", p.CreatedBefore, &filter.CreatedBefore},
+		{"created_after", p.CreatedAfter, &filter.CreatedAfter},
+	}
+	for _, d := range dates {
+		if d.value == "" {
+			continue
+		}
+		t, err := parseFilterTime(d.value)
+		if err != nil {
+			return nil, newFilterError(d.field, d.value, i18n.FilterInvalidDate)
+		}
+		*d.dest = &t
+	}
+
+	if filter.DueAfter != nil && filter.DueBefore != nil && !filter.DueAfter.Before(*filter.DueBefore) {
+		return nil, newFilterError("due_after", p.DueAfter, i18n.FilterInvalidRange, "due_before")
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return nil, newFilterError("created_after", p.CreatedAfter, i18n.FilterInvalidRange, "created_before")
+	}
+
+	if p.Overdue != "" {
+		overdue, err := strconv.ParseBool(p.Overdue)
+		if err != nil {
+			return nil, newFilterError("overdue", p.Overdue, i18n.FilterInvalidBool)
+		}
+		filter.Overdue = overdue
+	}
+
+	return filter, nil
+}
+
+// IsValid проверяет, что приоритет входит в допустимый набор
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh:
+		return true
+	}
+	return false
+}
+
+// ValidateStatuses проверяет, что все статусы фильтра есть в автомате статусов
+func (f *TaskFilter) ValidateStatuses(workflow *Workflow) error {
+	for _, s := range f.Statuses {
+		if !workflow.IsValid(s) {
+			return newFilterError("status", string(s), i18n.FilterInvalidValue, workflow.String())
+		}
+	}
+	return nil
+}
+
+// splitList разбирает список значений через запятую, пропуская пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFilterTime принимает дату без времени (полночь UTC) или полную метку времени RFC3339
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
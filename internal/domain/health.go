@@ -0,0 +1,27 @@
+package domain
+
+// HealthStatus состояние сервиса или его зависимости
+type HealthStatus string
+
+const (
+	HealthOK   HealthStatus = "ok"
+	HealthFail HealthStatus = "fail"
+)
+
+// DependencyCheck результат проверки одной зависимости
+type DependencyCheck struct {
+	Status     HealthStatus `json:"status"`
+	DurationMs int64        `json:"duration_ms"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// Readiness готовность сервиса принимать запросы с результатами проверок зависимостей по имени
+type Readiness struct {
+	Status HealthStatus                `json:"status"`
+	Checks map[string]*DependencyCheck `json:"checks"`
+}
+
+// Ready сервис может обслуживать запросы
+func (r *Readiness) Ready() bool {
+	return r.Status != HealthFail
+}
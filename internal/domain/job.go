@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// JobRunStatus результат запуска фоновой задачи
+type JobRunStatus string
+
+const (
+	JobRunSuccess JobRunStatus = "success"
+	JobRunFailed  JobRunStatus = "failed" // Все попытки завершились ошибкой или превысили таймаут
+)
+
+// JobRun сведения о последнем запуске фоновой задачи; общие для всех реплик
+type JobRun struct {
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Status     JobRunStatus `json:"status"`
+	Attempts   int          `json:"attempts"`        // Попыток, включая первую
+	Rows       int64        `json:"rows"`            // Обработано строк
+	Error      string       `json:"error,omitempty"` // Ошибка последней попытки
+	Instance   string       `json:"instance"`        // Реплика, выполнившая запуск
+}
+
+// JobInfo состояние фоновой задачи для GET /admin/jobs
+type JobInfo struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Timeout  string    `json:"timeout"`
+	Retries  int       `json:"retries"`
+	NextRun  time.Time `json:"next_run"`
+	LastRun  *JobRun   `json:"last_run"` // null, если задача ещё не запускалась
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"strings"
+)
+
+const (
+	DefaultPageLimit = 20  // Размер страницы по умолчанию
+	MaxPageLimit     = 100 // Максимальный размер страницы
+)
+
+// ErrInvalidCursor возвращается для испорченного курсора или курсора от другой сортировки
+var ErrInvalidCursor = &Error{Kind: ErrValidation, Code: CodeInvalidCursor, Key: i18n.InvalidCursor, Field: "cursor"}
+
+// Поля, по которым разрешена сортировка задач
+const (
+	SortByDueDate   = "due_date"
+	SortByPriority  = "priority"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	SortByTitle     = "title"
+)
+
+// TaskSort описывает сортировку списка задач
+type TaskSort struct {
+	Field string
+	Desc  bool
+}
+
+// String возвращает сортировку в формате query-параметра: "field" или "-field"
+func (s TaskSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// DefaultTaskSort сортировка по умолчанию: сначала новые задачи
+var DefaultTaskSort = TaskSort{Field: SortByCreatedAt, Desc: true}
+
+// ParseTaskSort разбирает параметр sort вида "due_date" (по возрастанию) или "-due_date" (по убыванию)
+func ParseTaskSort(value string) (TaskSort, error) {
+	if value == "" {
+		return DefaultTaskSort, nil
+	}
+
+	sort := TaskSort{Field: value}
+	if strings.HasPrefix(value, "-") {
+		sort = TaskSort{Field: strings.TrimPrefix(value, "-"), Desc: true}
+	}
+
+	switch sort.Field {
+	case SortByDueDate, SortByPriority, SortByCreatedAt, SortByUpdatedAt, SortByTitle:
+		return sort, nil
+	}
+
+	return TaskSort{}, NewFieldError("sort", i18n.InvalidSort, value)
+}
+
+// PageRequest параметры постраничной выборки
+type PageRequest struct {
+	Limit     int      // Размер страницы
+	Cursor    string   // Непрозрачный курсор, полученный в next_cursor предыдущей страницы
+	Sort      TaskSort // Сортировка
+	SkipTotal bool     // Не считать общее количество (для выгрузки всех страниц)
+}
+
+// NewPageRequest создаёт параметры страницы, ограничивая limit серверным максимумом
+func NewPageRequest(limit int, cursor string, sort TaskSort) *PageRequest {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	return &PageRequest{
+		Limit:  limit,
+		Cursor: cursor,
+		Sort:   sort,
+	}
+}
+
+// TaskPage страница списка задач
+type TaskPage struct {
+	Items      []*Task `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	Total      int     `json:"total"`
+}
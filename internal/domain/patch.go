@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// TaskPatch изменения задачи: nil — поле не меняется
+type TaskPatch struct {
+	Title       *string
+	Description *string // Указатель на пустую строку очищает описание
+	Status      *Status
+	Priority    *Priority
+	DueDate     *time.Time
+}
+
+// IsEmpty сообщает, что патч не меняет ни одного поля
+func (p *TaskPatch) IsEmpty() bool {
+	return p.Title == nil && p.Description == nil && p.Status == nil && p.Priority == nil && p.DueDate == nil
+}
+
+// FullTaskPatch патч, заменяющий все изменяемые поля задачи значениями task
+func FullTaskPatch(task *Task) *TaskPatch {
+	return &TaskPatch{
+		Title:       &task.Title,
+		Description: &task.Description,
+		Status:      &task.Status,
+		Priority:    &task.Priority,
+		DueDate:     &task.DueDate,
+	}
+}
+
+// readOnlyTaskFields поля, которые сервер заполняет сам; в патче они игнорируются,
+// чтобы клиент мог отправить обратно полученную задачу целиком
+var readOnlyTaskFields = map[string]bool{
+	"id":           true,
+	"owner_id":     true,
+	"created_at":   true,
+	"updated_at":   true,
+	"version":      true,
+	"deleted_at":   true,
+	"started_at":   true,
+	"completed_at": true,
+}
+
+// ParseTaskMergePatch разбирает JSON Merge Patch (RFC 7396) задачи.
+// null очищает описание; обязательные поля очистить нельзя
+func ParseTaskMergePatch(data []byte) (*TaskPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, NewValidationError(i18n.PatchNotObject)
+	}
+
+	patch := &TaskPatch{}
+	for name, raw := range fields {
+		if readOnlyTaskFields[name] {
+			continue
+		}
+
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		var dest interface{}
+		switch name {
+		case "title":
+			patch.Title = new(string)
+			dest = patch.Title
+		case "description":
+			patch.Description = new(string)
+			if isNull {
+				continue
+			}
+			dest = patch.Description
+		case "status":
+			patch.Status = new(Status)
+			dest = patch.Status
+		case "priority":
+			patch.Priority = new(Priority)
+			dest = patch.Priority
+		case "due_date":
+			patch.DueDate = new(time.Time)
+			dest = patch.DueDate
+		default:
+			return nil, NewFieldError(name, i18n.PatchUnknownField, name)
+		}
+
+		if isNull {
+			return nil, NewFieldError(name, i18n.PatchFieldNotNullable, name)
+		}
+		if err := json.Unmarshal(raw, dest); err != nil {
+			return nil, NewFieldError(name, i18n.PatchInvalidValue, name)
+		}
+	}
+
+	return patch, nil
+}
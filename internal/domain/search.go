@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSearchQueryLength максимальная длина поискового запроса в символах
+const MaxSearchQueryLength = 256
+
+// ErrInvalidSearchQuery возвращается для пустого или слишком длинного поискового запроса
+var ErrInvalidSearchQuery = &Error{
+	Kind:  ErrValidation,
+	Code:  CodeInvalidSearchQuery,
+	Key:   i18n.InvalidSearchQuery,
+	Args:  []interface{}{MaxSearchQueryLength},
+	Field: "q",
+}
+
+// TaskSearchResult задача, найденная полнотекстовым поиском
+type TaskSearchResult struct {
+	*Task
+	Rank      float32 `json:"rank"`      // Релевантность задачи запросу
+	Highlight string  `json:"highlight"` // Фрагмент названия и описания с найденными словами в <b></b>
+}
+
+// TaskSearchPage страница результатов поиска, отсортированная по релевантности
+type TaskSearchPage struct {
+	Items      []*TaskSearchResult `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+	Total      int                 `json:"total"`
+}
+
+// NormalizeSearchQuery обрезает пробелы и проверяет длину поискового запроса
+func NormalizeSearchQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return "", ErrInvalidSearchQuery
+	}
+	return query, nil
+}
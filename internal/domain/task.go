@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"GoTasker/internal/i18n"
+	"time"
+)
 
 type Status string
 type Priority string
@@ -17,14 +20,35 @@ const (
 
 // Task представляет задачу с различными атрибутами.
 type Task struct {
-	ID          int64     `json:"id,omitempty" db:"id"`                   // Уникальный идентификатор задачи в базе данных (auto increment).
-	Title       string    `json:"title,omitempty" db:"title"`             // Название задачи.
-	Description string    `json:"description,omitempty" db:"description"` // Описание задачи (опционально).
-	Status      Status    `json:"status,omitempty" db:"status"`           // Статус задачи (значения: pending, in_progress, done).
-	Priority    Priority  `json:"priority,omitempty" db:"priority"`       // Приоритет задачи (значения: low, medium, high).
-	DueDate     time.Time `json:"due_date" db:"due_date"`                 // Дата завершения задачи.
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`             // Дата создания задачи в базе данных.
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`             // Дата последнего обновления задачи в базе данных.
+	ID          int64      `json:"id,omitempty" db:"id"`                     // Уникальный идентификатор задачи в базе данных (auto increment).
+	OwnerID     int64      `json:"owner_id,omitempty" db:"owner_id"`         // Идентификатор пользователя-владельца задачи.
+	Title       string     `json:"title,omitempty" db:"title"`               // Название задачи.
+	Description string     `json:"description,omitempty" db:"description"`   // Описание задачи (опционально).
+	Status      Status     `json:"status,omitempty" db:"status"`             // Статус задачи (pending, in_progress, done и статусы из TASK_STATUS_TRANSITIONS).
+	Priority    Priority   `json:"priority,omitempty" db:"priority"`         // Приоритет задачи (значения: low, medium, high).
+	DueDate     time.Time  `json:"due_date" db:"due_date"`                   // Дата завершения задачи.
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`               // Дата создания задачи в базе данных.
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`               // Дата последнего обновления задачи в базе данных.
+	Version     int64      `json:"version,omitempty" db:"version"`           // Версия задачи, увеличивается при каждом изменении (отдаётся как ETag).
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`     // Время перемещения в корзину; nil — задача не удалена.
+	StartedAt   *time.Time `json:"started_at,omitempty" db:"started_at"`     // Первый перевод в in_progress; заполняется триггером БД.
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"` // Перевод в done; сбрасывается, если задачу вернули в работу. Заполняется триггером БД.
+}
+
+// ErrVersionMismatch возвращается, если задачу изменили после того, как клиент получил её версию
+var ErrVersionMismatch = NewError(ErrConflict, CodeVersionMismatch, i18n.VersionMismatch)
+
+// VersionConflictError конфликт версий при обновлении задачи, содержит актуальное состояние задачи
+type VersionConflictError struct {
+	Current *Task
+}
+
+func (e *VersionConflictError) Error() string {
+	return ErrVersionMismatch.Error()
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionMismatch
 }
 
 // CreateTaskRequest сугубо для swagger
@@ -36,32 +60,33 @@ type CreateTaskRequest struct {
 	DueDate     string `json:"due_date" example:"2025-05-03T00:00:00Z"`
 }
 
-// TaskFilter структура для фильтрации задач
-type TaskFilter struct {
-	Status   string `json:"status,omitempty"`
-	Priority string `json:"priority,omitempty"`
-	DueDate  string `json:"due_date,omitempty"`
-	Title    string `json:"title,omitempty"`
+// AnalyticsTasksResponse структура для сбора аналитики задач
+type AnalyticsTasksResponse struct {
+	StatusCounts     map[string]int `json:"status_counts"`
+	LeadTime         DurationStats  `json:"lead_time"`  // От создания до выполнения
+	CycleTime        DurationStats  `json:"cycle_time"` // От начала работы до выполнения
+	ReportLastPeriod *ReportPeriod  `json:"report_last_period"`
 }
 
-func NewTaskFilter(status string, priority string, dueDate string, title string) *TaskFilter {
-	return &TaskFilter{
-		Status:   status,
-		Priority: priority,
-		DueDate:  dueDate,
-		Title:    title,
-	}
+// DurationStats распределение длительностей выполненных задач в секундах; при Count == 0 все значения нулевые
+type DurationStats struct {
+	Count          int     `json:"count"`
+	AverageSeconds float64 `json:"average_seconds"`
+	MedianSeconds  float64 `json:"median_seconds"`
+	P90Seconds     float64 `json:"p90_seconds"`
+	P95Seconds     float64 `json:"p95_seconds"`
 }
 
-// AnalyticsTasksResponse структура для сбора аналитики задач
-type AnalyticsTasksResponse struct {
-	StatusCounts         map[string]int `json:"status_counts"`
-	AverageExecutionTime string         `json:"average_execution_time"`
-	ReportLastPeriod     *ReportPeriod  `json:"report_last_period"`
+// ExecutionTimeStats время выполнения задач: lead time считается от создания, cycle time — от начала работы
+type ExecutionTimeStats struct {
+	LeadTime  DurationStats
+	CycleTime DurationStats
 }
 
 // ReportPeriod структура для хранения количества завершённых и просроченных задач за указанный период
 type ReportPeriod struct {
-	CompletedTasks int `json:"completed_tasks"`
-	OverdueTasks   int `json:"overdue_tasks"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	CompletedTasks int       `json:"completed_tasks"` // Выполнено в периоде
+	OverdueTasks   int       `json:"overdue_tasks"`   // Срок истёк в периоде, а задача не была выполнена к сроку
 }
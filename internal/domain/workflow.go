@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"GoTasker/internal/i18n"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// statusNamePattern допустимый формат имени статуса; совпадает с CHECK в миграции 000006
+var statusNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// StatusTransitions статусы, в которые можно перевести задачу из статуса From
+type StatusTransitions struct {
+	From Status
+	To   []Status
+}
+
+// Workflow конечный автомат статусов задачи: набор статусов, начальный статус и разрешённые переходы
+type Workflow struct {
+	initial     Status
+	statuses    []Status
+	transitions map[Status][]Status
+}
+
+// DefaultTransitions переходы по умолчанию: выполненную задачу можно только вернуть в работу
+var DefaultTransitions = []StatusTransitions{
+	{From: StatusPending, To: []Status{StatusInProgress, StatusDone}},
+	{From: StatusInProgress, To: []Status{StatusPending, StatusDone}},
+	{From: StatusDone, To: []Status{StatusInProgress}},
+}
+
+// NewWorkflow проверяет описание переходов и создаёт автомат статусов.
+// Статус done обязателен: по нему считаются просроченные и выполненные задачи
+func NewWorkflow(initial Status, rules []StatusTransitions) (*Workflow, error) {
+	w := &Workflow{
+		initial:     initial,
+		transitions: make(map[Status][]Status, len(rules)),
+	}
+
+	declare := func(s Status) error {
+		if !statusNamePattern.MatchString(string(s)) {
+			return fmt.Errorf("недопустимое имя статуса %q: ожидаются строчные латинские буквы, цифры и _", s)
+		}
+		if !w.IsValid(s) {
+			w.statuses = append(w.statuses, s)
+		}
+		return nil
+	}
+
+	for _, rule := range rules {
+		if _, ok := w.transitions[rule.From]; ok {
+			return nil, fmt.Errorf("переходы из статуса %s описаны дважды", rule.From)
+		}
+		if err := declare(rule.From); err != nil {
+			return nil, err
+		}
+		w.transitions[rule.From] = rule.To
+	}
+	for _, rule := range rules {
+		for _, to := range rule.To {
+			if err := declare(to); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !w.IsValid(initial) {
+		return nil, fmt.Errorf("начальный статус %s не входит в набор статусов", initial)
+	}
+	if !w.IsValid(StatusDone) {
+		return nil, fmt.Errorf("набор статусов должен содержать %s", StatusDone)
+	}
+
+	return w, nil
+}
+
+// DefaultWorkflow автомат со статусами pending, in_progress, done и переходами DefaultTransitions
+func DefaultWorkflow() *Workflow {
+	w, err := NewWorkflow(StatusPending, DefaultTransitions)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// Initial статус новой задачи, если он не указан
+func (w *Workflow) Initial() Status {
+	return w.initial
+}
+
+// Statuses все статусы в порядке объявления
+func (w *Workflow) Statuses() []Status {
+	return w.statuses
+}
+
+// IsValid проверяет, что статус входит в набор
+func (w *Workflow) IsValid(s Status) bool {
+	for _, status := range w.statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed статусы, в которые можно перейти из from
+func (w *Workflow) Allowed(from Status) []Status {
+	return w.transitions[from]
+}
+
+// CanTransition сообщает, разрешён ли переход; оставить статус прежним можно всегда
+func (w *Workflow) CanTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range w.transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition возвращает ошибку ErrInvalidTransition с допустимыми статусами в Details, если переход запрещён
+func (w *Workflow) CheckTransition(from, to Status) error {
+	if !w.IsValid(to) {
+		return NewFieldError("status", i18n.TaskInvalidStatus, to)
+	}
+	if w.CanTransition(from, to) {
+		return nil
+	}
+
+	err := NewError(ErrConflict, CodeInvalidTransition, i18n.StatusTransitionForbidden, from, to)
+	err.Field = "status"
+	for _, s := range w.Allowed(from) {
+		err.Details = append(err.Details, string(s))
+	}
+	return err
+}
+
+// String перечисляет статусы через запятую, например для сообщений об ошибках
+func (w *Workflow) String() string {
+	names := make([]string, len(w.statuses))
+	for i, s := range w.statuses {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseStatusTransitions разбирает переходы из строки вида "pending:in_progress,done;done:in_progress".
+// Статус без исходящих переходов записывается как "archived:"
+func ParseStatusTransitions(value string) ([]StatusTransitions, error) {
+	var rules []StatusTransitions
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("ожидается статус:переходы, получено %q", part)
+		}
+
+		rule := StatusTransitions{From: Status(strings.TrimSpace(from))}
+		for _, s := range splitList(to) {
+			rule.To = append(rule.To, Status(s))
+		}
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("не описано ни одного статуса")
+	}
+	return rules, nil
+}
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"sync"
+)
+
+// Handler обработчик изменения задач. Вызывается синхронно, поэтому должен быть быстрым
+// и сам обрабатывать свои ошибки: запись задачи к этому моменту уже зафиксирована
+type Handler func(ctx context.Context, change domain.TaskChange)
+
+// Bus внутрипроцессная шина изменений задач
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe добавляет обработчик изменений
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// PublishTaskChange передаёт изменение всем подписчикам в порядке подписки
+func (b *Bus) PublishTaskChange(ctx context.Context, change domain.TaskChange) {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, change)
+	}
+}
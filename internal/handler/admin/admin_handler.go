@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type JobScheduler interface {
+	Jobs(ctx context.Context) []domain.JobInfo
+}
+
+type AdminHandler struct {
+	scheduler JobScheduler
+}
+
+func NewAdminHandler(scheduler JobScheduler) *AdminHandler {
+	return &AdminHandler{
+		scheduler: scheduler,
+	}
+}
+
+// @Summary Фоновые задачи
+// @Description Возвращает фоновые задачи с расписанием, ближайшим запуском и результатом последнего запуска на любой реплике.
+// @Description Доступно только администраторам (ADMIN_USER_IDS)
+// @Tags Администрирование
+// @Produce json
+// @Success 200 {object} map[string][]domain.JobInfo
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Доступно только администраторам"
+// @Router /admin/jobs [get]
+// @Security bearerAuth
+func (h *AdminHandler) Jobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": h.scheduler.Jobs(c.Request.Context())})
+}
@@ -5,10 +5,13 @@ import (
 	"context"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"time"
 )
 
 type TaskAnalyticsUseCase interface {
-	GetAnalytics(ctx context.Context) (*domain.AnalyticsTasksResponse, error)
+	GetAnalytics(ctx context.Context, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error)
+	GetTimeSeries(ctx context.Context, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) (*domain.TimeSeries, error)
+	GetBurndown(ctx context.Context, period *domain.AnalyticsPeriod, priorities []domain.Priority) (*domain.Burndown, error)
 }
 
 type TaskAnalyticsHandler struct {
@@ -22,26 +25,116 @@ func NewAnalyticsHandler(taskAnalyticsUseCase TaskAnalyticsUseCase) *TaskAnalyti
 }
 
 // @Summary Получение аналитики
-// @Description Возвращает аналитические данные по задачам
+// @Description Возвращает аналитические данные по задачам за период: по умолчанию за последние 7 дней.
+// @Description Количество задач по статусам считается на текущий момент, время выполнения — по задачам, выполненным в периоде
 // @Tags Аналитика
 // @Produce json
+// @Param period query string false "Период до текущего момента: 7d, 30d или 90d" default(7d)
+// @Param from query string false "Начало периода (YYYY-MM-DD или RFC3339); нельзя совмещать с period"
+// @Param to query string false "Конец периода (YYYY-MM-DD включительно или RFC3339); по умолчанию — текущий момент"
 // @Success 200 {object} domain.AnalyticsTasksResponse
+// @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /analytics [get]
 // @Security bearerAuth
 func (h *TaskAnalyticsHandler) GetAnalytics(c *gin.Context) {
 	const op = "internal.handler.analytics_handler.GetAnalytics"
 
+	period, err := parseAnalyticsPeriod(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
 	ctx := c.Request.Context()
 
-	analytics, err := h.taskAnalyticsUseCase.GetAnalytics(ctx)
+	analytics, err := h.taskAnalyticsUseCase.GetAnalytics(ctx, period)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Не удалось получить аналитику",
-		})
-
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, analytics)
 }
+
+// @Summary Временной ряд задач
+// @Description Возвращает количество созданных, выполненных и просроченных задач по дням или неделям периода для графиков.
+// @Description Интервалы без задач возвращаются с нулями
+// @Tags Аналитика
+// @Produce json
+// @Param bucket query string false "Размер интервала: day или week (недели начинаются с понедельника)" default(day)
+// @Param period query string false "Период до текущего момента: 7d, 30d или 90d" default(7d)
+// @Param from query string false "Начало периода (YYYY-MM-DD или RFC3339); нельзя совмещать с period"
+// @Param to query string false "Конец периода (YYYY-MM-DD включительно или RFC3339); по умолчанию — текущий момент"
+// @Success 200 {object} domain.TimeSeries
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /analytics/timeseries [get]
+// @Security bearerAuth
+func (h *TaskAnalyticsHandler) GetTimeSeries(c *gin.Context) {
+	period, err := parseAnalyticsPeriod(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	bucket, err := domain.ParseTimeBucket(c.Query("bucket"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	series, err := h.taskAnalyticsUseCase.GetTimeSeries(c.Request.Context(), period, bucket)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// @Summary Данные burndown и burnup
+// @Description Возвращает по дням периода остаток задач (не в статусе done), выполненные задачи и идеальную линию сгорания.
+// @Description Данные берутся из ежедневных снимков; дни без снимка возвращаются с null
+// @Tags Аналитика
+// @Produce json
+// @Param priority query string false "Приоритеты через запятую: low, medium, high; по умолчанию — все задачи"
+// @Param period query string false "Период до текущего момента: 7d, 30d или 90d" default(7d)
+// @Param from query string false "Начало периода (YYYY-MM-DD или RFC3339); нельзя совмещать с period"
+// @Param to query string false "Конец периода (YYYY-MM-DD включительно или RFC3339); по умолчанию — текущий момент"
+// @Success 200 {object} domain.Burndown
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /analytics/burndown [get]
+// @Security bearerAuth
+func (h *TaskAnalyticsHandler) GetBurndown(c *gin.Context) {
+	period, err := parseAnalyticsPeriod(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// Приоритеты проверяются так же, как в фильтре списка задач
+	filter, err := domain.ParseTaskFilter(domain.TaskFilterParams{Priority: c.Query("priority")})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	burndown, err := h.taskAnalyticsUseCase.GetBurndown(c.Request.Context(), period, filter.Priorities)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, burndown)
+}
+
+// parseAnalyticsPeriod разбирает параметры period, from и to
+func parseAnalyticsPeriod(c *gin.Context) (*domain.AnalyticsPeriod, error) {
+	return domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{
+		Period: c.Query("period"),
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+	}, time.Now())
+}
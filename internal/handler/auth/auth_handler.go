@@ -2,24 +2,26 @@ package auth
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
 	"context"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
-const (
-	ErrMsgEmptyUsername   = "имя не может быть пустым"
-	ErrMsgEmptyEmail      = "email не может быть пустым"
-	ErrMsgEmptyPassword   = "не указан пароль"
-	ErrMsgEmailExists     = "email уже используется"
-	ErrMsgUserNotFound    = "пользователь не найден"
-	ErrMsgInvalidPassword = "неверный пароль"
-	ErrMsgServerError     = "Не удалось выполнить операцию. Попробуйте позже."
-)
+// errInvalidRequest тело запроса не удалось разобрать
+var errInvalidRequest = domain.NewValidationError(i18n.InvalidRequest)
 
 type UserAuthUseCase interface {
 	Register(ctx context.Context, user *domain.User) error
 	Login(ctx context.Context, email, password string) (string, string, error)
+	Refresh(ctx context.Context, refreshToken string) (string, string, error)
+	Logout(ctx context.Context) error
+	LogoutAll(ctx context.Context) error
+}
+
+// RefreshRequest тело запроса на обновление токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 type UserAuthHandler struct {
@@ -48,40 +50,17 @@ func (h *UserAuthHandler) Register(c *gin.Context) {
 
 	var user domain.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Невалидные JSON"})
+		_ = c.Error(errInvalidRequest)
 		return
 	}
 	ctx := c.Request.Context()
 
-	err := h.authUseCase.Register(ctx, &user)
-	if err != nil {
-		switch err.Error() {
-		case ErrMsgEmptyUsername:
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				gin.H{"error": "Имя не может быть пустым"})
-		case ErrMsgEmptyEmail:
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				gin.H{"error": "Email не может быть пустым"})
-		case ErrMsgEmptyPassword:
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				gin.H{"error": "Пароль не может быть пустым"})
-		case ErrMsgEmailExists:
-			c.AbortWithStatusJSON(http.StatusConflict,
-				gin.H{"error": "Пользователь с таким email уже существует"})
-		case ErrMsgUserNotFound:
-			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				gin.H{"error": "Пользователь не найден"})
-		case ErrMsgInvalidPassword:
-			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				gin.H{"error": "Неверный пароль"})
-		default:
-			c.AbortWithStatusJSON(http.StatusInternalServerError,
-				gin.H{"error": ErrMsgServerError})
-		}
+	if err := h.authUseCase.Register(ctx, &user); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Пользователь успешно зарегистрирован"})
+	c.JSON(http.StatusCreated, gin.H{"message": i18n.T(i18n.FromContext(ctx), i18n.UserRegistered)})
 }
 
 // @Summary Авторизация пользователя
@@ -100,25 +79,49 @@ func (h *UserAuthHandler) Login(c *gin.Context) {
 
 	var user domain.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest,
-			gin.H{"error": "Невалидные данные"},
-		)
-
+		_ = c.Error(errInvalidRequest)
 		return
 	}
 	ctx := c.Request.Context()
 
 	accessToken, refreshToken, err := h.authUseCase.Login(ctx, user.Email, user.Password)
 	if err != nil {
-		switch err.Error() {
-		case ErrMsgUserNotFound, ErrMsgInvalidPassword:
-			c.AbortWithStatusJSON(http.StatusUnauthorized,
-				gin.H{"error": "Неверный email или пароль"})
-		default:
-			c.AbortWithStatusJSON(http.StatusInternalServerError,
-				gin.H{"error": "Не удалось выполнить операцию. Попробуйте позже."},
-			)
-		}
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		gin.H{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+		},
+	)
+}
+
+// @Summary Обновление токенов
+// @Description Обменивает refresh токен на новую пару access/refresh токенов. Повторное использование refresh токена отзывает сессию
+// @Tags Аутентификация
+// @Accept json
+// @Produce json
+// @Param request body RefreshRequest true "Refresh токен"
+// @Success 200 {object} map[string]string "Токены доступа"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 401 {object} map[string]string "Невалидный или отозванный refresh токен"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /auth/refresh [post]
+func (h *UserAuthHandler) Refresh(c *gin.Context) {
+	const op = "internal.handler.auth.Refresh"
+
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(errInvalidRequest)
+		return
+	}
+	ctx := c.Request.Context()
+
+	accessToken, refreshToken, err := h.authUseCase.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
@@ -129,3 +132,43 @@ func (h *UserAuthHandler) Login(c *gin.Context) {
 		},
 	)
 }
+
+// @Summary Выход из системы
+// @Description Отзывает access токен текущего запроса и refresh токены его сессии
+// @Tags Аутентификация
+// @Produce json
+// @Success 204 "Сессия завершена"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /auth/logout [post]
+// @Security bearerAuth
+func (h *UserAuthHandler) Logout(c *gin.Context) {
+	const op = "internal.handler.auth.Logout"
+
+	if err := h.authUseCase.Logout(c.Request.Context()); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Выход из всех сессий
+// @Description Отзывает все токены пользователя во всех сессиях, например после смены пароля
+// @Tags Аутентификация
+// @Produce json
+// @Success 204 "Все сессии завершены"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /auth/logout-all [post]
+// @Security bearerAuth
+func (h *UserAuthHandler) LogoutAll(c *gin.Context) {
+	const op = "internal.handler.auth.LogoutAll"
+
+	if err := h.authUseCase.LogoutAll(c.Request.Context()); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
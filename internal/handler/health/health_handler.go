@@ -0,0 +1,49 @@
+package health
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ReadinessChecker interface {
+	Readiness(ctx context.Context) *domain.Readiness
+}
+
+type HealthHandler struct {
+	checker ReadinessChecker
+}
+
+func NewHealthHandler(checker ReadinessChecker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// @Summary Liveness
+// @Description Процесс запущен и обрабатывает запросы. Зависимости не проверяются
+// @Tags Состояние
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": domain.HealthOK})
+}
+
+// @Summary Readiness
+// @Description Проверяет PostgreSQL, версию схемы и Redis. Отказ любой зависимости — 503
+// @Tags Состояние
+// @Produce json
+// @Success 200 {object} domain.Readiness "ok"
+// @Failure 503 {object} domain.Readiness "fail"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	readiness := h.checker.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if !readiness.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, readiness)
+}
@@ -2,22 +2,29 @@ package tasks
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type TaskUseCase interface {
 	Create(ctx context.Context, task *domain.Task) error
-	Update(ctx context.Context, task *domain.Task) error
+	GetByID(ctx context.Context, id int64) (*domain.Task, error)
+	History(ctx context.Context, id int64) ([]*domain.TaskEvent, error)
+	Update(ctx context.Context, task *domain.Task) (*domain.Task, error)
+	Patch(ctx context.Context, id, version int64, patch *domain.TaskPatch) (*domain.Task, error)
 	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error)
+	Purge(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) (*domain.Task, error)
+	Trash(ctx context.Context, page *domain.PageRequest) (*domain.TaskPage, error)
+	GetAll(ctx context.Context, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error)
+	Search(ctx context.Context, query string, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskSearchPage, error)
+	Export(ctx context.Context, yield func(tasks []*domain.Task) error) error
 	Import(ctx context.Context, tasks []*domain.Task) (int, []string, error)
 }
 
@@ -48,87 +55,186 @@ func (h *TaskHandler) Create(c *gin.Context) {
 	var task domain.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
 		slog.Error(op, "невалидный JSON", slog.String("err", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный JSON"})
+		_ = c.Error(errInvalidJSON)
 		return
 	}
 
 	ctx := c.Request.Context()
 	if err := h.useCase.Create(ctx, &task); err != nil {
 		slog.Error(op, "ошибка создания задачи", slog.String("err", err.Error()))
+		_ = c.Error(err)
+		return
+	}
 
-		if strings.Contains(err.Error(), "название задачи не может быть пустым") ||
-			strings.Contains(err.Error(), "приоритет задачи не может быть пустым") ||
-			strings.Contains(err.Error(), "не указана дата завершения задачи") ||
-			strings.Contains(err.Error(), "невалидный статус задачи") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	setETag(c, &task)
+	c.JSON(http.StatusCreated, task)
+}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// @Summary Получение задачи
+// @Description Возвращает задачу по ID
+// @Tags Задачи
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} domain.Task
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tasks/{id} [get]
+// @Security bearerAuth
+func (h *TaskHandler) GetByID(c *gin.Context) {
+	const op = "internal.handler.task_handler.GetByID"
+
+	id, ok := parseTaskID(c, op)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusCreated, task)
+	ctx := c.Request.Context()
+	task, err := h.useCase.GetByID(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary История задачи
+// @Description Возвращает события задачи в порядке их записи: кто и когда изменил задачу и значения полей до и после.
+// @Description История сохраняется и после удаления задачи
+// @Tags Задачи
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {array} domain.TaskEvent
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tasks/{id}/history [get]
+// @Security bearerAuth
+func (h *TaskHandler) History(c *gin.Context) {
+	const op = "internal.handler.task_handler.History"
+
+	id, ok := parseTaskID(c, op)
+	if !ok {
+		return
+	}
+
+	events, err := h.useCase.History(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
 }
 
 // @Summary Обновление задачи
-// @Description Обновляет существующую задачу по ID
+// @Description Полностью заменяет изменяемые поля задачи: title, description, status, priority, due_date. Отсутствующее описание очищается
 // @Tags Задачи
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
-// @Param task body domain.CreateTaskRequest true "Обновленные параметры задачи"
+// @Param If-Match header string true "ETag задачи, полученный при чтении, например \"3\"; * — без проверки версии"
+// @Param task body domain.CreateTaskRequest true "Новое состояние задачи"
 // @Success 200 {object} domain.Task
+// @Header 200 {string} ETag "Новая версия задачи"
 // @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 412 {object} map[string]interface{} "Задача изменена другим запросом, в current — актуальное состояние"
+// @Failure 428 {object} map[string]string "Не передан заголовок If-Match"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /tasks/{id} [put]
 // @Security bearerAuth
 func (h *TaskHandler) Update(c *gin.Context) {
 	const op = "internal.handler.task_handler.Update"
 
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID задачи не указан"})
-		return
-	}
-
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		slog.Error(op, "не удалось преобразовать id", slog.String("err", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный ID задачи"})
+	id, version, ok := parseUpdateTarget(c, op)
+	if !ok {
 		return
 	}
 
 	var updatedTask domain.Task
-	if err = c.ShouldBindJSON(&updatedTask); err != nil {
+	if err := c.ShouldBindJSON(&updatedTask); err != nil {
 		slog.Error(op, "невалидный JSON", slog.String("err", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный JSON"})
+		_ = c.Error(errInvalidJSON)
 		return
 	}
 
 	updatedTask.ID = id
+	updatedTask.Version = version
 	ctx := c.Request.Context()
-	if err = h.useCase.Update(ctx, &updatedTask); err != nil {
+	task, err := h.useCase.Update(ctx, &updatedTask)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-		customErr := fmt.Sprintf("задача с id %v не найдена", id)
-		if strings.Contains(err.Error(), customErr) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Частичное обновление задачи
+// @Description Применяет JSON Merge Patch (RFC 7396): меняются только переданные поля, null очищает описание
+// @Tags Задачи
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param If-Match header string true "ETag задачи, полученный при чтении, например \"3\"; * — без проверки версии"
+// @Param patch body domain.CreateTaskRequest true "Изменяемые поля задачи"
+// @Success 200 {object} domain.Task
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 404 {object} map[string]string "Задача не найдена"
+// @Failure 412 {object} map[string]interface{} "Задача изменена другим запросом, в current — актуальное состояние"
+// @Failure 415 {object} map[string]string "Неподдерживаемый Content-Type"
+// @Failure 428 {object} map[string]string "Не передан заголовок If-Match"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tasks/{id} [patch]
+// @Security bearerAuth
+func (h *TaskHandler) Patch(c *gin.Context) {
+	const op = "internal.handler.task_handler.Patch"
+
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		_ = c.Error(domain.NewError(domain.ErrValidation, domain.CodeUnsupportedMediaType, i18n.UnsupportedMediaType))
+		return
+	}
+
+	id, version, ok := parseUpdateTarget(c, op)
+	if !ok {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		slog.Error(op, "не удалось прочитать тело запроса", slog.String("err", err.Error()))
+		_ = c.Error(errInvalidJSON)
+		return
+	}
+
+	patch, err := domain.ParseTaskMergePatch(body)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	task, err := h.useCase.Patch(ctx, id, version, patch)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
-	updatedTask.UpdatedAt = time.Now()
 
-	c.JSON(http.StatusOK, updatedTask)
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
 }
 
 // @Summary Удаление задачи
-// @Description Удаляет задачу по ID
+// @Description Перемещает задачу в корзину. С permanent=true удаляет задачу безвозвратно, в том числе из корзины
 // @Tags Задачи
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param permanent query bool false "Удалить безвозвратно"
 // @Success 204 "Задача успешно удалена"
 // @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 404 {object} map[string]string "Задача не найдена"
@@ -138,70 +244,183 @@ func (h *TaskHandler) Update(c *gin.Context) {
 func (h *TaskHandler) Delete(c *gin.Context) {
 	const op = "internal.handler.task_handler.Delete"
 
-	idStr := c.Param("id")
-	if idStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID задачи не указан"})
+	id, ok := parseTaskID(c, op)
+	if !ok {
 		return
 	}
 
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		slog.Error(op, "не удалось преобразовать id", slog.String("err", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный ID задачи"})
-		return
+	permanent := false
+	if value := c.Query("permanent"); value != "" {
+		var err error
+		if permanent, err = strconv.ParseBool(value); err != nil {
+			_ = c.Error(domain.NewFieldError("permanent", i18n.FilterInvalidBool, "permanent", value))
+			return
+		}
 	}
 
 	ctx := c.Request.Context()
-	if err = h.useCase.Delete(ctx, id); err != nil {
+	remove := h.useCase.Delete
+	if permanent {
+		remove = h.useCase.Purge
+	}
+	if err := remove(ctx, id); err != nil {
 		slog.Error(op, "ошибка удаления задачи", slog.String("err", err.Error()))
-
-		customErr := fmt.Sprintf("задача с id %d не найдена для удаления", id)
-		if strings.Contains(err.Error(), customErr) {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// @Summary Восстановление задачи из корзины
+// @Description Возвращает удалённую задачу из корзины в список задач
+// @Tags Корзина
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} domain.Task
+// @Header 200 {string} ETag "Новая версия задачи"
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 404 {object} map[string]string "Задачи нет в корзине"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tasks/{id}/restore [post]
+// @Security bearerAuth
+func (h *TaskHandler) Restore(c *gin.Context) {
+	const op = "internal.handler.task_handler.Restore"
+
+	id, ok := parseTaskID(c, op)
+	if !ok {
+		return
+	}
+
+	task, err := h.useCase.Restore(c.Request.Context(), id)
+	if err != nil {
+		slog.Error(op, "ошибка восстановления задачи", slog.String("err", err.Error()))
+		_ = c.Error(err)
+		return
+	}
+
+	setETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
+
+// @Summary Корзина
+// @Description Возвращает страницу удалённых задач. Задачи удаляются из корзины безвозвратно через TRASH_RETENTION_DAYS дней
+// @Tags Корзина
+// @Produce json
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: due_date, priority, created_at, updated_at, title; префикс '-' — по убыванию" default(-created_at)
+// @Success 200 {object} domain.TaskPage
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tasks/trash [get]
+// @Security bearerAuth
+func (h *TaskHandler) Trash(c *gin.Context) {
+	const op = "internal.handler.task_handler.Trash"
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	tasks, err := h.useCase.Trash(c.Request.Context(), page)
+	if err != nil {
+		slog.Error(op, "ошибка получения корзины", slog.String("err", err.Error()))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
 // @Summary Получение списка задач
-// @Description Возвращает список всех задач с возможностью фильтрации
+// @Description Возвращает страницу задач с возможностью фильтрации и сортировки
 // @Tags Задачи
 // @Produce json
-// @Param status query string false "Фильтр по статусу"
-// @Param priority query string false "Фильтр по приоритету"
-// @Param due_date query string false "Фильтр по дате завершения"
-// @Param title query string false "Фильтр по названию"
-// @Success 200 {array} domain.Task
+// @Param status query string false "Фильтр по статусам через запятую: pending,in_progress,done"
+// @Param priority query string false "Фильтр по приоритетам через запятую: low,medium,high"
+// @Param due_date query string false "Срок в указанный день (YYYY-MM-DD)"
+// @Param due_before query string false "Срок раньше даты (YYYY-MM-DD или RFC3339)"
+// @Param due_after query string false "Срок позже даты (YYYY-MM-DD или RFC3339)"
+// @Param created_before query string false "Создана раньше даты (YYYY-MM-DD или RFC3339)"
+// @Param created_after query string false "Создана позже даты (YYYY-MM-DD или RFC3339)"
+// @Param overdue query bool false "Только просроченные невыполненные задачи"
+// @Param title query string false "Поиск по названию"
+// @Param description query string false "Поиск по описанию"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: due_date, priority, created_at, updated_at, title; префикс '-' — по убыванию" default(-created_at)
+// @Success 200 {object} domain.TaskPage
+// @Failure 400 {object} map[string]string "Ошибка валидации"
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /tasks [get]
 // @Security bearerAuth
 func (h *TaskHandler) GetAll(c *gin.Context) {
 	const op = "internal.handler.task_handler.GetAll"
 
-	status := c.DefaultQuery("status", "")
-	priority := c.DefaultQuery("priority", "")
-	dueDate := c.DefaultQuery("due_date", "")
-	title := c.DefaultQuery("title", "")
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
 
-	filter := domain.NewTaskFilter(status, priority, dueDate, title)
+	page, err := parsePageRequest(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
 	ctx := c.Request.Context()
-	tasks, err := h.useCase.GetAll(ctx, filter)
+	tasks, err := h.useCase.GetAll(ctx, filter, page)
 	if err != nil {
 		slog.Error(op, "ошибка получения списка задач", slog.String("err", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Не удалось получить список задач. Попробуйте позже.",
-		})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, tasks)
 }
 
+// @Summary Поиск задач
+// @Description Полнотекстовый поиск по названию и описанию с учётом словоформ (русский и английский). Результаты отсортированы по релевантности, найденные слова выделены в highlight тегом <b>
+// @Tags Задачи
+// @Produce json
+// @Param q query string true "Поисковый запрос: слова, \"точная фраза\", -исключение, or"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param status query string false "Фильтр по статусам через запятую"
+// @Param priority query string false "Фильтр по приоритетам через запятую"
+// @Success 200 {object} domain.TaskSearchPage
+// @Failure 400 {object} map[string]string "Ошибка валидации"
+// @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
+// @Router /tasks/search [get]
+// @Security bearerAuth
+func (h *TaskHandler) Search(c *gin.Context) {
+	const op = "internal.handler.task_handler.Search"
+
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
+
+	limit, err := parseLimit(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	page := domain.NewPageRequest(limit, c.Query("cursor"), domain.DefaultTaskSort)
+
+	ctx := c.Request.Context()
+	result, err := h.useCase.Search(ctx, c.Query("q"), filter, page)
+	if err != nil {
+		slog.Error(op, "ошибка поиска задач", slog.String("err", err.Error()))
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // @Summary Экспорт задач
 // @Description Экспортирует все задачи в JSON файл
 // @Tags Задачи
@@ -214,23 +433,59 @@ func (h *TaskHandler) Export(c *gin.Context) { // отдаём
 	const op = "internal.handler.task_handler.Export"
 
 	ctx := c.Request.Context()
-	tasks, err := h.useCase.GetAll(ctx, &domain.TaskFilter{})
+	written := 0
+
+	// Задачи пишутся в ответ постранично, не собирая весь список в памяти
+	err := h.useCase.Export(ctx, func(tasks []*domain.Task) error {
+		if written == 0 {
+			c.Header("Content-Disposition", "attachment; filename=tasks.json")
+			c.Header("Content-Type", "application/json")
+			c.Status(http.StatusOK)
+			if _, err := c.Writer.WriteString("[\n"); err != nil {
+				return err
+			}
+		}
+
+		for _, task := range tasks {
+			data, err := json.MarshalIndent(task, "  ", "  ")
+			if err != nil {
+				return err
+			}
+
+			sep := "  "
+			if written > 0 {
+				sep = ",\n  "
+			}
+			if _, err = c.Writer.WriteString(sep); err != nil {
+				return err
+			}
+			if _, err = c.Writer.Write(data); err != nil {
+				return err
+			}
+			written++
+		}
+
+		c.Writer.Flush()
+		return nil
+	})
 	if err != nil {
-		slog.Error(op, "ошибка получения списка задач", slog.String("err", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Не удалось получить список задач. Попробуйте позже.",
-		})
+		slog.Error(op, "ошибка экспорта задач", slog.String("err", err.Error()))
+		if !c.Writer.Written() {
+			_ = c.Error(err)
+			return
+		}
+		// Заголовки уже отправлены: массив остаётся незакрытым, и клиент не примет неполный файл за целый
+		c.Abort()
 		return
 	}
 
-	data, err := json.MarshalIndent(tasks, "", "  ")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось обработать задачи"})
+	if !c.Writer.Written() {
+		c.Header("Content-Disposition", "attachment; filename=tasks.json")
+		c.Data(http.StatusOK, "application/json", []byte("[]"))
 		return
 	}
 
-	c.Header("Content-Disposition", "attachment; filename=tasks.json")
-	c.Data(http.StatusOK, "application/json", data)
+	_, _ = c.Writer.WriteString("\n]")
 }
 
 // @Summary Импорт задач
@@ -252,14 +507,14 @@ func (h *TaskHandler) Import(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
 		slog.Error("не удалось получить файл", slog.String("err", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "не удалось получить файл"})
+		_ = c.Error(domain.NewFieldError("file", i18n.ImportFileMissing))
 		return
 	}
 
 	src, err := file.Open()
 	if err != nil {
 		slog.Error("не удалось открыть файл", slog.String("err", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "не удалось открыть файл"})
+		_ = c.Error(err)
 		return
 	}
 	defer src.Close()
@@ -267,23 +522,134 @@ func (h *TaskHandler) Import(c *gin.Context) {
 	var tasks []*domain.Task
 	if err = json.NewDecoder(src).Decode(&tasks); err != nil {
 		slog.Error("ошибка парсинга JSON", slog.String("err", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "невалидный JSON формат"})
+		_ = c.Error(domain.NewFieldError("file", i18n.ImportInvalidJSON))
 		return
 	}
 
 	inserted, skipped, err := h.useCase.Import(ctx, tasks)
 	if err != nil {
 		slog.Error("ошибка импорта задач", slog.String("err", err.Error()))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":         "ошибка при импорте задач",
-			"skipped_tasks": skipped,
-		})
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":        "Импорт успешно завершен",
+		"message":        i18n.T(i18n.FromContext(ctx), i18n.ImportCompleted),
 		"inserted_tasks": inserted,
 		"skipped_tasks":  skipped,
 	})
 }
+
+// errInvalidJSON тело запроса не удалось разобрать как JSON
+var errInvalidJSON = domain.NewValidationError(i18n.InvalidJSON)
+
+// parseTaskID разбирает id задачи из пути; при ошибке передаёт её в ErrorHandler
+func parseTaskID(c *gin.Context, op string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		slog.Error(op, "не удалось преобразовать id", slog.String("err", err.Error()))
+		_ = c.Error(domain.NewFieldError("id", i18n.InvalidTaskID))
+		return 0, false
+	}
+
+	return id, true
+}
+
+// parseTaskFilter разбирает параметры фильтра; при ошибке передаёт её в ErrorHandler
+func parseTaskFilter(c *gin.Context) (*domain.TaskFilter, bool) {
+	filter, err := domain.ParseTaskFilter(domain.TaskFilterParams{
+		Status:        c.Query("status"),
+		Priority:      c.Query("priority"),
+		DueDate:       c.Query("due_date"),
+		DueBefore:     c.Query("due_before"),
+		DueAfter:      c.Query("due_after"),
+		CreatedBefore: c.Query("created_before"),
+		CreatedAfter:  c.Query("created_after"),
+		Overdue:       c.Query("overdue"),
+		Title:         c.Query("title"),
+		Description:   c.Query("description"),
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return nil, false
+	}
+
+	return filter, true
+}
+
+// parsePageRequest разбирает параметры limit, cursor и sort
+func parsePageRequest(c *gin.Context) (*domain.PageRequest, error) {
+	limit, err := parseLimit(c)
+	if err != nil {
+		return nil, err
+	}
+
+	sort, err := domain.ParseTaskSort(c.Query("sort"))
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewPageRequest(limit, c.Query("cursor"), sort), nil
+}
+
+// parseLimit разбирает параметр limit; 0 означает размер страницы по умолчанию
+func parseLimit(c *gin.Context) (int, error) {
+	limitStr := c.Query("limit")
+	if limitStr == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return 0, domain.NewFieldError("limit", i18n.InvalidLimit, limitStr)
+	}
+
+	return limit, nil
+}
+
+// parseUpdateTarget разбирает id задачи и обязательный If-Match; при ошибке передаёт её в ErrorHandler
+func parseUpdateTarget(c *gin.Context, op string) (int64, int64, bool) {
+	id, ok := parseTaskID(c, op)
+	if !ok {
+		return 0, 0, false
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		_ = c.Error(domain.NewError(domain.ErrValidation, domain.CodeIfMatchRequired, i18n.IfMatchRequired))
+		return 0, 0, false
+	}
+
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		_ = c.Error(err)
+		return 0, 0, false
+	}
+
+	return id, version, true
+}
+
+// setETag отдаёт версию задачи в заголовке ETag
+func setETag(c *gin.Context, task *domain.Task) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(task.Version, 10)))
+}
+
+// parseIfMatch извлекает версию из If-Match вида "3"; для * возвращает 0 (без проверки версии).
+// Слабые теги W/"3" отклоняются: If-Match требует строгого сравнения (RFC 9110, 13.1.1)
+func parseIfMatch(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return 0, nil
+	}
+
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, domain.NewFieldError("If-Match", i18n.InvalidIfMatch, value)
+	}
+
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, domain.NewFieldError("If-Match", i18n.InvalidIfMatch, value)
+	}
+
+	return version, nil
+}
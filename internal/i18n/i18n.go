@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang язык сообщений API
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// Fallback язык, на котором написан каталог; используется, если перевода на нужный язык нет
+const Fallback = RU
+
+// Supported проверяет, что для языка есть переводы
+func (l Lang) Supported() bool {
+	return l == RU || l == EN
+}
+
+// T возвращает сообщение key на языке lang, подставляя args.
+// При отсутствии перевода используется Fallback, для неизвестного ключа — сам ключ
+func T(lang Lang, key Key, args ...interface{}) string {
+	translations, ok := catalog[key]
+	if !ok {
+		return string(key)
+	}
+
+	format, ok := translations[lang]
+	if !ok {
+		format = translations[Fallback]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+type langKey struct{}
+
+// ContextWithLang возвращает контекст с языком текущего запроса
+func ContextWithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// FromContext возвращает язык запроса; если он не задан — Fallback
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(langKey{}).(Lang); ok {
+		return lang
+	}
+	return Fallback
+}
+
+// ParseAcceptLanguage выбирает поддерживаемый язык из заголовка Accept-Language
+// с учётом весов q. Если подходящего языка нет, возвращает defaultLang
+func ParseAcceptLanguage(header string, defaultLang Lang) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		// en-US, en_GB -> en; * означает «любой», то есть язык по умолчанию
+		primary, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+		lang := Lang(strings.ToLower(primary))
+		if primary == "*" {
+			lang = defaultLang
+		}
+		if lang.Supported() {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return defaultLang
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].lang
+}
@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCatalogComplete(t *testing.T) {
+	for key, translations := range catalog {
+		ru, ok := translations[RU]
+		if !assert.True(t, ok, "нет русского перевода для %s", key) {
+			continue
+		}
+		en, ok := translations[EN]
+		if !assert.True(t, ok, "нет английского перевода для %s", key) {
+			continue
+		}
+		assert.Equal(t, strings.Count(ru, "%"), strings.Count(en, "%"), "разное число аргументов в переводах %s", key)
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "задача с id 5 не найдена", T(RU, TaskNotFoundID, 5))
+	assert.Equal(t, "task with id 5 not found", T(EN, TaskNotFoundID, 5))
+	assert.Equal(t, "задача не найдена", T(Lang("de"), TaskNotFound))
+	assert.Equal(t, "unknown_key", T(EN, Key("unknown_key")))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Lang
+	}{
+		{"", RU},
+		{"en", EN},
+		{"en-US,en;q=0.9", EN},
+		{"de-DE,de;q=0.9,en;q=0.8", EN},
+		{"ru;q=0.5,en;q=0.8", EN},
+		{"en;q=0,ru", RU},
+		{"fr", RU},
+		{"*", RU},
+		{"EN_gb", EN},
+		{"en;q=abc", RU},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseAcceptLanguage(tt.header, RU))
+		})
+	}
+
+	assert.Equal(t, EN, ParseAcceptLanguage("fr", EN))
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Fallback, FromContext(context.Background()))
+	assert.Equal(t, EN, FromContext(ContextWithLang(context.Background(), EN)))
+}
@@ -0,0 +1,324 @@
+package i18n
+
+// Key стабильный ключ сообщения в каталоге
+type Key string
+
+// Ошибки аутентификации
+const (
+	Unauthenticated            Key = "unauthenticated"
+	InvalidCredentials         Key = "invalid_credentials"
+	EmailTaken                 Key = "email_taken"
+	UserNotFound               Key = "user_not_found"
+	TokenMissing               Key = "token_missing"
+	InvalidToken               Key = "invalid_token"
+	InvalidAuthorizationHeader Key = "invalid_authorization_header"
+	TokenExpired               Key = "token_expired"
+	WrongTokenType             Key = "wrong_token_type"
+	TokenRevoked               Key = "token_revoked"
+	TokenCheckUnavailable      Key = "token_check_unavailable"
+	InvalidRefreshToken        Key = "invalid_refresh_token"
+	SessionRevoked             Key = "session_revoked"
+	RefreshTokenReused         Key = "refresh_token_reused"
+	UsernameRequired           Key = "username_required"
+	EmailRequired              Key = "email_required"
+	PasswordRequired           Key = "password_required"
+	AdminRequired              Key = "admin_required"
+)
+
+// Ошибки задач и параметров запроса
+const (
+	TaskNotFound         Key = "task_not_found"
+	TaskNotFoundID       Key = "task_not_found_id"
+	VersionMismatch      Key = "version_mismatch"
+	TaskIDRequired       Key = "task_id_required"
+	TaskTitleRequired    Key = "task_title_required"
+	TaskPriorityRequired Key = "task_priority_required"
+	TaskDueDateRequired  Key = "task_due_date_required"
+	TaskStatusRequired   Key = "task_status_required"
+	TaskInvalidStatus    Key = "task_invalid_status"
+	TaskInvalidPriority  Key = "task_invalid_priority"
+
+	StatusTransitionForbidden Key = "status_transition_forbidden"
+
+	PatchEmpty            Key = "patch_empty"
+	PatchNotObject        Key = "patch_not_object"
+	PatchUnknownField     Key = "patch_unknown_field"
+	PatchFieldNotNullable Key = "patch_field_not_nullable"
+	PatchInvalidValue     Key = "patch_invalid_value"
+
+	InvalidCursor      Key = "invalid_cursor"
+	InvalidSearchQuery Key = "invalid_search_query"
+	InvalidSort        Key = "invalid_sort"
+	InvalidLimit       Key = "invalid_limit"
+	FilterInvalidValue Key = "filter_invalid_value"
+	FilterInvalidDate  Key = "filter_invalid_date"
+	FilterInvalidRange Key = "filter_invalid_range"
+	FilterInvalidBool  Key = "filter_invalid_bool"
+
+	AnalyticsPeriodConflict Key = "analytics_period_conflict"
+	AnalyticsFromRequired   Key = "analytics_from_required"
+	AnalyticsPeriodTooLong  Key = "analytics_period_too_long"
+
+	InvalidJSON          Key = "invalid_json"
+	InvalidRequest       Key = "invalid_request"
+	InvalidTaskID        Key = "invalid_task_id"
+	IfMatchRequired      Key = "if_match_required"
+	InvalidIfMatch       Key = "invalid_if_match"
+	UnsupportedMediaType Key = "unsupported_media_type"
+
+	ImportFileMissing  Key = "import_file_missing"
+	ImportInvalidJSON  Key = "import_invalid_json"
+	ImportNoValidTasks Key = "import_no_valid_tasks"
+	ImportTaskRejected Key = "import_task_rejected"
+
+	InternalError Key = "internal_error"
+)
+
+// Сообщения об успешных операциях
+const (
+	UserRegistered  Key = "user_registered"
+	ImportCompleted Key = "import_completed"
+)
+
+// catalog переводы сообщений; у каждого ключа обязателен перевод на Fallback
+var catalog = map[Key]map[Lang]string{
+	Unauthenticated: {
+		RU: "пользователь не аутентифицирован",
+		EN: "user is not authenticated",
+	},
+	InvalidCredentials: {
+		RU: "неверный email или пароль",
+		EN: "invalid email or password",
+	},
+	EmailTaken: {
+		RU: "email уже используется",
+		EN: "email is already in use",
+	},
+	UserNotFound: {
+		RU: "пользователь не найден",
+		EN: "user not found",
+	},
+	TokenMissing: {
+		RU: "отсутствует токен авторизации",
+		EN: "authorization token is missing",
+	},
+	InvalidToken: {
+		RU: "невалидный токен",
+		EN: "invalid token",
+	},
+	InvalidAuthorizationHeader: {
+		RU: "неверный формат заголовка Authorization",
+		EN: "invalid Authorization header format",
+	},
+	TokenExpired: {
+		RU: "срок действия токена истёк",
+		EN: "token has expired",
+	},
+	WrongTokenType: {
+		RU: "требуется access токен",
+		EN: "an access token is required",
+	},
+	TokenRevoked: {
+		RU: "токен отозван",
+		EN: "token has been revoked",
+	},
+	TokenCheckUnavailable: {
+		RU: "не удалось проверить токен, попробуйте позже",
+		EN: "could not verify the token, please try again later",
+	},
+	InvalidRefreshToken: {
+		RU: "невалидный refresh токен",
+		EN: "invalid refresh token",
+	},
+	SessionRevoked: {
+		RU: "сессия отозвана, выполните вход заново",
+		EN: "session has been revoked, please sign in again",
+	},
+	RefreshTokenReused: {
+		RU: "повторное использование refresh токена, сессия отозвана",
+		EN: "refresh token reuse detected, session has been revoked",
+	},
+	UsernameRequired: {
+		RU: "имя не может быть пустым",
+		EN: "username must not be empty",
+	},
+	EmailRequired: {
+		RU: "email не может быть пустым",
+		EN: "email must not be empty",
+	},
+	PasswordRequired: {
+		RU: "не указан пароль",
+		EN: "password is required",
+	},
+	AdminRequired: {
+		RU: "доступно только администраторам",
+		EN: "administrator access is required",
+	},
+
+	TaskNotFound: {
+		RU: "задача не найдена",
+		EN: "task not found",
+	},
+	TaskNotFoundID: {
+		RU: "задача с id %d не найдена",
+		EN: "task with id %d not found",
+	},
+	VersionMismatch: {
+		RU: "задача была изменена другим запросом",
+		EN: "the task was modified by another request",
+	},
+	TaskIDRequired: {
+		RU: "id задачи не может быть нулевым",
+		EN: "task id must not be zero",
+	},
+	TaskTitleRequired: {
+		RU: "название задачи не может быть пустым",
+		EN: "task title must not be empty",
+	},
+	TaskPriorityRequired: {
+		RU: "приоритет задачи не может быть пустым",
+		EN: "task priority must not be empty",
+	},
+	TaskDueDateRequired: {
+		RU: "не указана дата завершения задачи",
+		EN: "task due date is required",
+	},
+	TaskStatusRequired: {
+		RU: "не указан статус задачи",
+		EN: "task status is required",
+	},
+	TaskInvalidStatus: {
+		RU: "невалидный статус задачи: %s",
+		EN: "invalid task status: %s",
+	},
+	TaskInvalidPriority: {
+		RU: "некорректный приоритет задачи: %s",
+		EN: "invalid task priority: %s",
+	},
+	StatusTransitionForbidden: {
+		RU: "переход из статуса %s в статус %s запрещён",
+		EN: "changing status from %s to %s is not allowed",
+	},
+
+	PatchEmpty: {
+		RU: "нет данных для обновления",
+		EN: "nothing to update",
+	},
+	PatchNotObject: {
+		RU: "тело запроса должно быть JSON объектом",
+		EN: "request body must be a JSON object",
+	},
+	PatchUnknownField: {
+		RU: "неизвестное поле задачи: %s",
+		EN: "unknown task field: %s",
+	},
+	PatchFieldNotNullable: {
+		RU: "поле %s нельзя очистить",
+		EN: "field %s cannot be cleared",
+	},
+	PatchInvalidValue: {
+		RU: "некорректное значение поля %s",
+		EN: "invalid value for field %s",
+	},
+
+	InvalidCursor: {
+		RU: "невалидный курсор",
+		EN: "invalid cursor",
+	},
+	InvalidSearchQuery: {
+		RU: "поисковый запрос должен быть непустым и не длиннее %d символов",
+		EN: "search query must be non-empty and at most %d characters long",
+	},
+	InvalidSort: {
+		RU: "недопустимое поле сортировки: %s",
+		EN: "unsupported sort field: %s",
+	},
+	InvalidLimit: {
+		RU: "невалидный limit: %s",
+		EN: "invalid limit: %s",
+	},
+	FilterInvalidValue: {
+		RU: "некорректный параметр %s=%q: допустимы %s",
+		EN: "invalid parameter %s=%q: allowed values are %s",
+	},
+	FilterInvalidDate: {
+		RU: "некорректный параметр %s=%q: ожидается дата YYYY-MM-DD или RFC3339",
+		EN: "invalid parameter %s=%q: expected a YYYY-MM-DD date or an RFC3339 timestamp",
+	},
+	FilterInvalidRange: {
+		RU: "некорректный параметр %s=%q: должен быть раньше %s",
+		EN: "invalid parameter %s=%q: must be earlier than %s",
+	},
+	FilterInvalidBool: {
+		RU: "некорректный параметр %s=%q: ожидается true или false",
+		EN: "invalid parameter %s=%q: expected true or false",
+	},
+	AnalyticsPeriodConflict: {
+		RU: "укажите либо period, либо from и to",
+		EN: "specify either period or from and to",
+	},
+	AnalyticsFromRequired: {
+		RU: "вместе с to нужно указать from",
+		EN: "from is required when to is set",
+	},
+	AnalyticsPeriodTooLong: {
+		RU: "период аналитики не может быть длиннее %d дней",
+		EN: "the analytics period cannot be longer than %d days",
+	},
+
+	InvalidJSON: {
+		RU: "невалидный JSON",
+		EN: "invalid JSON",
+	},
+	InvalidRequest: {
+		RU: "невалидные данные",
+		EN: "invalid request data",
+	},
+	InvalidTaskID: {
+		RU: "невалидный ID задачи",
+		EN: "invalid task ID",
+	},
+	IfMatchRequired: {
+		RU: "требуется заголовок If-Match с ETag задачи",
+		EN: "an If-Match header with the task ETag is required",
+	},
+	InvalidIfMatch: {
+		RU: "невалидный If-Match: %s",
+		EN: "invalid If-Match: %s",
+	},
+	UnsupportedMediaType: {
+		RU: "ожидается Content-Type application/merge-patch+json",
+		EN: "expected Content-Type application/merge-patch+json",
+	},
+
+	ImportFileMissing: {
+		RU: "не удалось получить файл",
+		EN: "could not read the uploaded file",
+	},
+	ImportInvalidJSON: {
+		RU: "невалидный JSON формат",
+		EN: "invalid JSON format",
+	},
+	ImportNoValidTasks: {
+		RU: "все задачи невалидны",
+		EN: "none of the tasks are valid",
+	},
+	ImportTaskRejected: {
+		RU: "Задача %d: %s",
+		EN: "Task %d: %s",
+	},
+
+	InternalError: {
+		RU: "Внутренняя ошибка сервера. Попробуйте позже.",
+		EN: "Internal server error. Please try again later.",
+	},
+
+	UserRegistered: {
+		RU: "Пользователь успешно зарегистрирован",
+		EN: "User registered successfully",
+	},
+	ImportCompleted: {
+		RU: "Импорт успешно завершен",
+		EN: "Import completed successfully",
+	},
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"time"
+)
+
+const namespace = "gotasker"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Количество HTTP-запросов по методу, маршруту и статусу ответа.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Время обработки HTTP-запросов по методу, маршруту и статусу ответа.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Время выполнения запросов к PostgreSQL по методу репозитория.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "analytics_cache_requests_total",
+		Help:      "Обращения к кэшу аналитики в Redis: hit — ответ из кэша, miss — агрегаты пересчитаны.",
+	}, []string{"kind", "result"})
+
+	jobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "background_job_runs_total",
+		Help:      "Запуски фоновых задач по результату.",
+	}, []string{"job", "result"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "background_job_duration_seconds",
+		Help:      "Время выполнения фоновых задач.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"})
+
+	jobRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "background_job_rows_total",
+		Help:      "Строки, обработанные фоновыми задачами: перемещённые в корзину, удалённые, записанные в снимок.",
+	}, []string{"job"})
+)
+
+// ObserveHTTPRequest учитывает обработанный HTTP-запрос
+func ObserveHTTPRequest(method, route, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// ObserveDBQuery учитывает время работы метода репозитория, начатого в start.
+// Вызывается через defer в начале метода: defer metrics.ObserveDBQuery(op, time.Now())
+func ObserveDBQuery(operation string, start time.Time) {
+	dbDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+// CacheHit учитывает ответ из кэша аналитики вида kind
+func CacheHit(kind string) {
+	cacheRequests.WithLabelValues(kind, "hit").Inc()
+}
+
+// CacheMiss учитывает пересчёт аналитики вида kind
+func CacheMiss(kind string) {
+	cacheRequests.WithLabelValues(kind, "miss").Inc()
+}
+
+// ObserveJob учитывает запуск фоновой задачи job, начатый в start и обработавший rows строк
+func ObserveJob(job string, start time.Time, rows int64, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	jobRuns.WithLabelValues(job, result).Inc()
+	jobDuration.WithLabelValues(job).Observe(time.Since(start).Seconds())
+	jobRows.WithLabelValues(job).Add(float64(rows))
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"log/slog"
+	"time"
+)
+
+// scrapeTimeout ограничивает запрос количества задач, чтобы медленная база не подвешивала сбор метрик
+const scrapeTimeout = 5 * time.Second
+
+var tasksDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "tasks"),
+	"Количество задач (без корзины) по статусу и приоритету.",
+	[]string{"status", "priority"}, nil,
+)
+
+type TaskCounter interface {
+	CountTasks(ctx context.Context) ([]domain.TaskCount, error)
+}
+
+// TaskCollector считает задачи по статусу и приоритету при каждом сборе метрик,
+// поэтому исчезнувшие сочетания не остаются в выдаче со старыми значениями
+type TaskCollector struct {
+	counter TaskCounter
+}
+
+func NewTaskCollector(counter TaskCounter) *TaskCollector {
+	return &TaskCollector{counter: counter}
+}
+
+func (c *TaskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tasksDesc
+}
+
+func (c *TaskCollector) Collect(ch chan<- prometheus.Metric) {
+	const op = "internal.metrics.task_collector.Collect"
+
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountTasks(ctx)
+	if err != nil {
+		slog.Error(op, "ошибка подсчёта задач для метрик", slog.String("err", err.Error()))
+		ch <- prometheus.NewInvalidMetric(tasksDesc, err)
+		return
+	}
+
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(count.Count), string(count.Status), string(count.Priority))
+	}
+}
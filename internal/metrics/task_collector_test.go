@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type stubTaskCounter struct {
+	counts []domain.TaskCount
+	err    error
+}
+
+func (s *stubTaskCounter) CountTasks(ctx context.Context) ([]domain.TaskCount, error) {
+	return s.counts, s.err
+}
+
+func TestTaskCollector(t *testing.T) {
+	counter := &stubTaskCounter{counts: []domain.TaskCount{
+		{Status: domain.StatusPending, Priority: domain.PriorityHigh, Count: 3},
+		{Status: domain.StatusDone, Priority: domain.PriorityLow, Count: 5},
+	}}
+	collector := NewTaskCollector(counter)
+
+	expected := `
+# HELP gotasker_tasks Количество задач (без корзины) по статусу и приоритету.
+# TYPE gotasker_tasks gauge
+gotasker_tasks{priority="high",status="pending"} 3
+gotasker_tasks{priority="low",status="done"} 5
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	t.Run("исчезнувшие сочетания пропадают из выдачи", func(t *testing.T) {
+		counter.counts = counter.counts[1:]
+		assert.Equal(t, 1, testutil.CollectAndCount(collector))
+	})
+
+	t.Run("ошибка базы возвращается как ошибка сбора", func(t *testing.T) {
+		counter.err = errors.New("нет соединения")
+		assert.Error(t, testutil.CollectAndCompare(collector, strings.NewReader("")))
+	})
+}
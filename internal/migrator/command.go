@@ -0,0 +1,87 @@
+package migrator
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Usage описание подкоманды migrate
+const Usage = `использование: migrate <команда>
+  up        применить все новые миграции
+  down [N]  откатить N последних миграций (по умолчанию 1)
+  goto N    перейти на версию N вверх или вниз
+  force N   записать версию N без выполнения миграций и снять dirty
+  status    показать текущую и последнюю версии схемы`
+
+// Command разобранная подкоманда migrate
+type Command struct {
+	Name    string
+	Version int // Для down — количество миграций, для goto и force — версия
+}
+
+// ParseCommand разбирает аргументы подкоманды migrate
+func ParseCommand(args []string) (Command, error) {
+	if len(args) == 0 {
+		return Command{}, fmt.Errorf("не указана команда")
+	}
+
+	cmd := Command{Name: args[0]}
+	switch cmd.Name {
+	case "up", "status":
+		if len(args) != 1 {
+			return Command{}, fmt.Errorf("команда %s не принимает аргументов", cmd.Name)
+		}
+		return cmd, nil
+	case "down":
+		cmd.Version = 1
+		if len(args) == 1 {
+			return cmd, nil
+		}
+	case "goto", "force":
+		if len(args) == 1 {
+			return Command{}, fmt.Errorf("команде %s нужна версия", cmd.Name)
+		}
+	default:
+		return Command{}, fmt.Errorf("неизвестная команда %q", cmd.Name)
+	}
+
+	if len(args) != 2 {
+		return Command{}, fmt.Errorf("лишние аргументы команды %s", cmd.Name)
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 || (cmd.Name == "down" && n == 0) {
+		return Command{}, fmt.Errorf("некорректное число %q", args[1])
+	}
+	cmd.Version = n
+
+	return cmd, nil
+}
+
+// Execute выполняет команду и пишет итоговое состояние схемы в out
+func (m *Migrator) Execute(cmd Command, out io.Writer) error {
+	var err error
+	switch cmd.Name {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down(cmd.Version)
+	case "goto":
+		err = m.Goto(uint(cmd.Version))
+	case "force":
+		err = m.Force(cmd.Version)
+	case "status":
+	default:
+		err = fmt.Errorf("неизвестная команда %q", cmd.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "версия схемы: %d, последняя миграция: %d, dirty: %t\n", status.Version, status.Latest, status.Dirty)
+	return err
+}
@@ -0,0 +1,43 @@
+package migrator
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	valid := []struct {
+		args []string
+		want Command
+	}{
+		{[]string{"up"}, Command{Name: "up"}},
+		{[]string{"status"}, Command{Name: "status"}},
+		{[]string{"down"}, Command{Name: "down", Version: 1}},
+		{[]string{"down", "3"}, Command{Name: "down", Version: 3}},
+		{[]string{"goto", "0"}, Command{Name: "goto", Version: 0}},
+		{[]string{"goto", "7"}, Command{Name: "goto", Version: 7}},
+		{[]string{"force", "10"}, Command{Name: "force", Version: 10}},
+	}
+	for _, tt := range valid {
+		cmd, err := ParseCommand(tt.args)
+		require.NoError(t, err, tt.args)
+		assert.Equal(t, tt.want, cmd, tt.args)
+	}
+
+	invalid := [][]string{
+		nil,
+		{"drop"},
+		{"up", "1"},
+		{"down", "0"},
+		{"down", "-1"},
+		{"down", "1", "2"},
+		{"goto"},
+		{"goto", "x"},
+		{"force"},
+	}
+	for _, args := range invalid {
+		_, err := ParseCommand(args)
+		assert.Error(t, err, args)
+	}
+}
@@ -0,0 +1,130 @@
+// Package migrator применяет встроенные миграции из пакета migrations к PostgreSQL
+package migrator
+
+import (
+	"GoTasker/migrations"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// lockTimeout сколько ждать advisory lock, пока миграции применяет другая реплика.
+// Переменная, а не константа, чтобы тесты могли сократить ожидание
+var lockTimeout = 5 * time.Minute
+
+// Status состояние схемы базы данных
+type Status struct {
+	Version uint // Текущая версия схемы, 0 — миграции не применялись
+	Dirty   bool // Последняя миграция упала на середине, нужен force
+	Latest  uint // Последняя встроенная миграция
+}
+
+// Migrator применяет миграции через отдельное соединение с базой. Все изменения схемы выполняются под
+// advisory lock PostgreSQL, поэтому реплики, запущенные одновременно, применяют миграции по очереди
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// New подключается к базе dsn; соединение закрывается в Close
+func New(dsn string) (*Migrator, error) {
+	const op = "internal.migrator.New"
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения встроенных миграций: %w", err)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к базе данных: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		_ = db.Close()
+		slog.Error(op, "ошибка подготовки миграций", slog.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка подготовки миграций: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		_ = driver.Close()
+		return nil, fmt.Errorf("ошибка подготовки миграций: %w", err)
+	}
+	m.LockTimeout = lockTimeout
+	m.Log = logger{}
+
+	return &Migrator{m: m}, nil
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// Down откатывает steps последних миграций
+func (m *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("количество откатываемых миграций должно быть положительным")
+	}
+	return ignoreNoChange(m.m.Steps(-steps))
+}
+
+// Goto переводит схему на версию version вверх или вниз
+func (m *Migrator) Goto(version uint) error {
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак dirty.
+// Нужна после ручного исправления упавшей миграции или для базы, созданной без schema_migrations
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+// Status возвращает текущую и последнюю доступную версии схемы
+func (m *Migrator) Status() (*Status, error) {
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	version, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return &Status{Latest: latest}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения версии схемы: %w", err)
+	}
+
+	return &Status{Version: version, Dirty: dirty, Latest: latest}, nil
+}
+
+// Close закрывает соединение с базой
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// logger передаёт сообщения migrate в slog
+type logger struct{}
+
+func (logger) Printf(format string, v ...interface{}) {
+	slog.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (logger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,54 @@
+package health
+
+import (
+	"GoTasker/internal/metrics"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"log/slog"
+	"time"
+)
+
+// undefinedTable код ошибки PostgreSQL для отсутствующей таблицы
+const undefinedTable = "42P01"
+
+type HealthPostgresRepo struct {
+	db *sql.DB
+}
+
+func NewHealthPostgresRepo(db *sql.DB) *HealthPostgresRepo {
+	return &HealthPostgresRepo{db: db}
+}
+
+// Ping проверяет соединение с базой данных
+func (r *HealthPostgresRepo) Ping(ctx context.Context) error {
+	const op = "internal.repository.postgres.health.Ping"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	if err := r.db.PingContext(ctx); err != nil {
+		slog.Error(op, "база данных недоступна", slog.String("err", err.Error()))
+		return fmt.Errorf("база данных недоступна: %w", err)
+	}
+
+	return nil
+}
+
+// SchemaVersion возвращает версию схемы из таблицы golang-migrate. Если миграции не применялись, версия 0
+func (r *HealthPostgresRepo) SchemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	const op = "internal.repository.postgres.health.SchemaVersion"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	err = r.db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	var pqErr *pq.Error
+	if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &pqErr) && pqErr.Code == undefinedTable) {
+		return 0, false, nil
+	}
+	if err != nil {
+		slog.Error(op, "ошибка получения версии схемы", slog.String("err", err.Error()))
+		return 0, false, fmt.Errorf("ошибка получения версии схемы: %w", err)
+	}
+
+	return version, dirty, nil
+}
@@ -0,0 +1,94 @@
+package tasks
+
+import (
+	"GoTasker/internal/domain"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// sortColumns SQL-выражения для полей сортировки и тип, к которому приводится значение курсора
+var sortColumns = map[string]struct {
+	expr string
+	cast string
+}{
+	domain.SortByDueDate:   {expr: "due_date", cast: "timestamptz"},
+	domain.SortByCreatedAt: {expr: "created_at", cast: "timestamptz"},
+	domain.SortByUpdatedAt: {expr: "updated_at", cast: "timestamptz"},
+	domain.SortByTitle:     {expr: "title", cast: "text"},
+	domain.SortByPriority: {
+		expr: "(CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 END)",
+		cast: "int",
+	},
+}
+
+// pageCursor позиция последней выданной задачи в выбранной сортировке
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// searchSort сортировка результатов поиска: сначала самые релевантные
+var searchSort = domain.TaskSort{Field: "rank", Desc: true}
+
+func encodeCursor(sort domain.TaskSort, task *domain.Task) string {
+	return encodeCursorValue(sort, sortValue(sort.Field, task), task.ID)
+}
+
+func encodeSearchCursor(result *domain.TaskSearchResult) string {
+	return encodeCursorValue(searchSort, strconv.FormatFloat(float64(result.Rank), 'g', -1, 32), result.ID)
+}
+
+func encodeCursorValue(sort domain.TaskSort, value string, id int64) string {
+	c := pageCursor{Sort: sort.String(), Value: value, ID: id}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string, sort domain.TaskSort) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	if c.Sort != sort.String() {
+		return nil, fmt.Errorf("%w: курсор получен для сортировки %q", domain.ErrInvalidCursor, c.Sort)
+	}
+
+	return &c, nil
+}
+
+func sortValue(field string, task *domain.Task) string {
+	switch field {
+	case domain.SortByDueDate:
+		return task.DueDate.Format(time.RFC3339Nano)
+	case domain.SortByUpdatedAt:
+		return task.UpdatedAt.Format(time.RFC3339Nano)
+	case domain.SortByTitle:
+		return task.Title
+	case domain.SortByPriority:
+		return strconv.Itoa(priorityRank(task.Priority))
+	default:
+		return task.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func priorityRank(p domain.Priority) int {
+	switch p {
+	case domain.PriorityLow:
+		return 1
+	case domain.PriorityMedium:
+		return 2
+	case domain.PriorityHigh:
+		return 3
+	}
+	return 0
+}
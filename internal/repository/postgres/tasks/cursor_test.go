@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"GoTasker/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	task := &domain.Task{
+		ID:        42,
+		Title:     "Task",
+		Priority:  domain.PriorityMedium,
+		DueDate:   time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC),
+		CreatedAt: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		sort  domain.TaskSort
+		value string
+	}{
+		{domain.DefaultTaskSort, "2024-12-01T00:00:00Z"},
+		{domain.TaskSort{Field: domain.SortByDueDate}, "2025-01-02T03:04:05.000006Z"},
+		{domain.TaskSort{Field: domain.SortByPriority, Desc: true}, "2"},
+		{domain.TaskSort{Field: domain.SortByTitle}, "Task"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			c, err := decodeCursor(encodeCursor(tt.sort, task), tt.sort)
+			require.NoError(t, err)
+			assert.Equal(t, int64(42), c.ID)
+			assert.Equal(t, tt.value, c.Value)
+		})
+	}
+}
+
+func TestCursor_Invalid(t *testing.T) {
+	t.Run("испорченный курсор", func(t *testing.T) {
+		_, err := decodeCursor("не-base64!", domain.DefaultTaskSort)
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+
+	t.Run("курсор от другой сортировки", func(t *testing.T) {
+		encoded := encodeCursor(domain.TaskSort{Field: domain.SortByTitle}, &domain.Task{ID: 1})
+		_, err := decodeCursor(encoded, domain.DefaultTaskSort)
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+}
@@ -13,8 +13,8 @@ type MockTaskAnalyticsRepo struct {
 }
 
 // GetTaskCountByStatus мок-метод для получения количества задач по статусам
-func (m *MockTaskAnalyticsRepo) GetTaskCountByStatus(ctx context.Context) (map[string]int, error) {
-	args := m.Called(ctx)
+func (m *MockTaskAnalyticsRepo) GetTaskCountByStatus(ctx context.Context, ownerID int64) (map[string]int, error) {
+	args := m.Called(ctx, ownerID)
 	val := args.Get(0)
 	if val == nil {
 		return nil, args.Error(1)
@@ -22,15 +22,18 @@ func (m *MockTaskAnalyticsRepo) GetTaskCountByStatus(ctx context.Context) (map[s
 	return val.(map[string]int), args.Error(1)
 }
 
-// GetAverageExecutionTime мок-метод для получения среднего времени выполнения
-func (m *MockTaskAnalyticsRepo) GetAverageExecutionTime(ctx context.Context) (string, error) {
-	args := m.Called(ctx)
-	return args.String(0), args.Error(1)
+// GetExecutionTimeStats мок-метод для получения времени выполнения задач
+func (m *MockTaskAnalyticsRepo) GetExecutionTimeStats(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ExecutionTimeStats, error) {
+	args := m.Called(ctx, ownerID, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExecutionTimeStats), args.Error(1)
 }
 
 // GetReportPeriod мок-метод для получения отчета за период
-func (m *MockTaskAnalyticsRepo) GetReportPeriod(ctx context.Context) (*domain.ReportPeriod, error) {
-	args := m.Called(ctx)
+func (m *MockTaskAnalyticsRepo) GetReportPeriod(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ReportPeriod, error) {
+	args := m.Called(ctx, ownerID, period)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -51,9 +54,9 @@ func TestTaskAnalyticsRepo_GetTaskCountByStatus(t *testing.T) {
 			string(domain.StatusDone):       2,
 		}
 
-		mockRepo.On("GetTaskCountByStatus", mock.Anything).Return(expectedCounts, nil)
+		mockRepo.On("GetTaskCountByStatus", mock.Anything, int64(1)).Return(expectedCounts, nil)
 
-		counts, err := mockRepo.GetTaskCountByStatus(context.Background())
+		counts, err := mockRepo.GetTaskCountByStatus(context.Background(), 1)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedCounts, counts)
@@ -63,10 +66,10 @@ func TestTaskAnalyticsRepo_GetTaskCountByStatus(t *testing.T) {
 	t.Run("ошибка при получении статистики", func(t *testing.T) {
 		mockRepo.ExpectedCalls = nil
 
-		mockRepo.On("GetTaskCountByStatus", mock.Anything).Return(nil, assert.AnError)
+		mockRepo.On("GetTaskCountByStatus", mock.Anything, int64(1)).Return(nil, assert.AnError)
 
 		// Выполнение метода
-		counts, err := mockRepo.GetTaskCountByStatus(context.Background())
+		counts, err := mockRepo.GetTaskCountByStatus(context.Background(), 1)
 
 		// Проверка результатов
 		assert.Error(t, err)
@@ -75,34 +78,37 @@ func TestTaskAnalyticsRepo_GetTaskCountByStatus(t *testing.T) {
 	})
 }
 
-func TestTaskAnalyticsRepo_GetAverageExecutionTime(t *testing.T) {
+func TestTaskAnalyticsRepo_GetExecutionTimeStats(t *testing.T) {
 	mockRepo := newMockRepo()
 
-	t.Run("успешное получение среднего времени выполнения задач", func(t *testing.T) {
+	t.Run("успешное получение времени выполнения задач", func(t *testing.T) {
 		// Тестовые данные
-		expectedTime := "2h 30m"
+		expectedStats := &domain.ExecutionTimeStats{
+			LeadTime:  domain.DurationStats{Count: 4, AverageSeconds: 9000, MedianSeconds: 7200, P90Seconds: 18000, P95Seconds: 19800},
+			CycleTime: domain.DurationStats{Count: 3, AverageSeconds: 3600, MedianSeconds: 3000, P90Seconds: 6000, P95Seconds: 6600},
+		}
 
 		// Настройка ожидания
-		mockRepo.On("GetAverageExecutionTime", mock.Anything).Return(expectedTime, nil)
+		mockRepo.On("GetExecutionTimeStats", mock.Anything, int64(1), mock.Anything).Return(expectedStats, nil)
 
 		// Выполнение метода
-		avgTime, err := mockRepo.GetAverageExecutionTime(context.Background())
+		stats, err := mockRepo.GetExecutionTimeStats(context.Background(), 1, nil)
 
 		// Проверка результатов
 		assert.NoError(t, err)
-		assert.Equal(t, expectedTime, avgTime)
+		assert.Equal(t, expectedStats, stats)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("ошибка при получении среднего времени выполнения задач", func(t *testing.T) {
+	t.Run("ошибка при получении времени выполнения задач", func(t *testing.T) {
 		mockRepo.ExpectedCalls = nil
 
-		mockRepo.On("GetAverageExecutionTime", mock.Anything).Return("", assert.AnError)
+		mockRepo.On("GetExecutionTimeStats", mock.Anything, int64(1), mock.Anything).Return(nil, assert.AnError)
 
-		avgTime, err := mockRepo.GetAverageExecutionTime(context.Background())
+		stats, err := mockRepo.GetExecutionTimeStats(context.Background(), 1, nil)
 
 		assert.Error(t, err)
-		assert.Empty(t, avgTime)
+		assert.Nil(t, stats)
 		mockRepo.AssertExpectations(t)
 	})
 }
@@ -117,9 +123,9 @@ func TestTaskAnalyticsRepo_GetReportPeriod(t *testing.T) {
 			OverdueTasks:   4,
 		}
 
-		mockRepo.On("GetReportPeriod", mock.Anything).Return(expectedReport, nil)
+		mockRepo.On("GetReportPeriod", mock.Anything, int64(1), mock.Anything).Return(expectedReport, nil)
 
-		report, err := mockRepo.GetReportPeriod(context.Background())
+		report, err := mockRepo.GetReportPeriod(context.Background(), 1, nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedReport, report)
@@ -129,9 +135,9 @@ func TestTaskAnalyticsRepo_GetReportPeriod(t *testing.T) {
 	t.Run("ошибка при получении отчета за последние 7 дней", func(t *testing.T) {
 		mockRepo.ExpectedCalls = nil
 
-		mockRepo.On("GetReportPeriod", mock.Anything).Return(nil, assert.AnError)
+		mockRepo.On("GetReportPeriod", mock.Anything, int64(1), mock.Anything).Return(nil, assert.AnError)
 
-		report, err := mockRepo.GetReportPeriod(context.Background())
+		report, err := mockRepo.GetReportPeriod(context.Background(), 1, nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, report)
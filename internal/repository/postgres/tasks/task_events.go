@@ -0,0 +1,124 @@
+package tasks
+
+import (
+	"GoTasker/internal/domain"
+	"GoTasker/internal/metrics"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// actorFromContext id пользователя, выполняющего действие; nil для фоновых задач без пользователя
+func actorFromContext(ctx context.Context) *int64 {
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &user.ID
+}
+
+// insertEvents записывает события истории в транзакции изменения задач; nil-события пропускаются
+func insertEvents(ctx context.Context, tx *sql.Tx, events ...*domain.TaskEvent) error {
+	var values []string
+	var args []interface{}
+
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+
+		changes, err := json.Marshal(event.Changes)
+		if err != nil {
+			return fmt.Errorf("не удалось сериализовать изменения задачи %d: %w", event.TaskID, err)
+		}
+
+		i := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i+1, i+2, i+3, i+4, i+5))
+		args = append(args, event.TaskID, event.OwnerID, event.ActorID, event.Type, changes)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO task_events (task_id, owner_id, actor_id, event_type, changes) VALUES ` + strings.Join(values, ", ")
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("не удалось записать историю задачи: %w", err)
+	}
+	return nil
+}
+
+// collectEvents выполняет в транзакции запрос, возвращающий колонки taskColumns,
+// и строит событие для каждой затронутой задачи
+func collectEvents(ctx context.Context, tx *sql.Tx, query string, args []interface{}, event func(task *domain.Task) *domain.TaskEvent) ([]*domain.TaskEvent, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.TaskEvent
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event(task))
+	}
+
+	return events, rows.Err()
+}
+
+// History возвращает события задачи владельца в порядке их записи.
+// История доступна и после удаления задачи
+func (r *TaskPostgresRepo) History(ctx context.Context, ownerID, taskID int64) ([]*domain.TaskEvent, error) {
+	const op = "internal.repository.postgres.task_repo.History"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	query := `
+		SELECT id, task_id, owner_id, actor_id, event_type, changes, created_at
+		FROM task_events
+		WHERE task_id = $1 AND owner_id = $2
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, taskID, ownerID)
+	if err != nil {
+		slog.Error(op, "не удалось получить историю задачи", slog.Int64("id", taskID), slog.String("err", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*domain.TaskEvent{}
+	for rows.Next() {
+		var (
+			event   domain.TaskEvent
+			actorID sql.NullInt64
+			changes []byte
+		)
+		err = rows.Scan(&event.ID, &event.TaskID, &event.OwnerID, &actorID, &event.Type, &changes, &event.CreatedAt)
+		if err != nil {
+			slog.Error(op, "не удалось извлечь событие задачи", slog.String("err", err.Error()))
+			return nil, err
+		}
+		if actorID.Valid {
+			event.ActorID = &actorID.Int64
+		}
+		if err = json.Unmarshal(changes, &event.Changes); err != nil {
+			slog.Error(op, "не удалось разобрать изменения задачи", slog.String("err", err.Error()))
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "ошибка при переборе строк", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return events, nil
+}
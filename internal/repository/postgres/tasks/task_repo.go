@@ -2,160 +2,396 @@ package tasks
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/metrics"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/lib/pq"
 	"log/slog"
 	"strings"
 	"time"
 )
 
+// taskColumns список колонок задачи в порядке, ожидаемом scanTask
+const taskColumns = `id, owner_id, title, description, status, priority, due_date, created_at, updated_at, version, deleted_at, started_at, completed_at`
+
 type TaskPostgresRepo struct {
 	db *sql.DB
 }
 
+// rowScanner общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask читает задачу из колонок taskColumns; extra получают значения колонок, следующих за ними
+func scanTask(row rowScanner, extra ...any) (*domain.Task, error) {
+	var task domain.Task
+	if err := row.Scan(append(taskFields(&task), extra...)...); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// taskFields указатели на поля задачи в порядке taskColumns
+func taskFields(task *domain.Task) []any {
+	return []any{
+		&task.ID,
+		&task.OwnerID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Priority,
+		&task.DueDate,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.Version,
+		&task.DeletedAt,
+		&task.StartedAt,
+		&task.CompletedAt,
+	}
+}
+
+// qualifiedTaskColumns taskColumns с префиксом таблицы alias, например "old.id, old.owner_id, ..."
+func qualifiedTaskColumns(alias string) string {
+	columns := strings.Split(taskColumns, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
 func NewTaskPostgresRepo(db *sql.DB) *TaskPostgresRepo {
 	return &TaskPostgresRepo{
 		db: db,
 	}
 }
 
-func (r *TaskPostgresRepo) Create(ctx context.Context, task *domain.Task) error {
+// Create сохраняет задачу и событие created в одной транзакции
+func (r *TaskPostgresRepo) Create(ctx context.Context, task *domain.Task) (err error) {
 	const op = "internal.repository.postgres.task_repo.Create"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
 	query := `
-		INSERT INTO tasks (title, description, status, priority, due_date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		INSERT INTO tasks (owner_id, title, description, status, priority, due_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, version, started_at, completed_at
 	`
 
-	if err := r.db.QueryRowContext(
+	if err = tx.QueryRowContext(
 		ctx, query,
+		task.OwnerID,
 		task.Title,
 		task.Description,
 		task.Status,
 		task.Priority,
 		task.DueDate,
 		task.CreatedAt,
-		task.UpdatedAt).Scan(&task.ID); err != nil {
+		task.UpdatedAt).Scan(&task.ID, &task.Version, &task.StartedAt, &task.CompletedAt); err != nil {
 		slog.Error(op, "не удалось сохранить задачу",
 			slog.String("title", task.Title),
 			slog.String("status", string(task.Status)),
 			slog.String("err", err.Error()))
 		return err
 	}
+
+	if err = insertEvents(ctx, tx, domain.NewTaskEvent(domain.TaskEventCreated, actorFromContext(ctx), nil, task)); err != nil {
+		slog.Error(op, "не удалось записать историю задачи", slog.String("err", err.Error()))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
 	return nil
 }
 
-func (r *TaskPostgresRepo) Update(ctx context.Context, updates map[string]interface{}) error {
-	const op = "internal.repository.postgres.task_repo.Update"
+func (r *TaskPostgresRepo) GetByID(ctx context.Context, ownerID, id int64) (*domain.Task, error) {
+	const op = "internal.repository.postgres.task_repo.GetByID"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
-	taskID := updates["id"]
-	var exists bool
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NULL`
 
-	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1)", taskID).Scan(&exists)
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id, ownerID))
 	if err != nil {
-		slog.Error(op, "ошибка при проверке существования задачи", slog.String("err", err.Error()))
-		return fmt.Errorf("не удалось проверить существование задачи: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewTaskNotFoundError(id)
+		}
+
+		slog.Error(op, "не удалось получить задачу", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, err
 	}
-	if !exists {
-		return fmt.Errorf("задача с id %v не найдена", taskID)
+
+	return task, nil
+}
+
+// Update применяет патч к задаче владельца, если её текущая версия равна version (0 — без проверки версии).
+// Блокировка строки, проверка версии и запись выполняются одним UPDATE, который возвращает и состояние
+// до изменения для события истории; текущая задача читается отдельно, только если строка не обновлена.
+// check, если задан, получает состояние задачи до изменения; его ошибка откатывает изменение
+func (r *TaskPostgresRepo) Update(ctx context.Context, ownerID, id, version int64, patch *domain.TaskPatch, check func(current *domain.Task) error) (_ *domain.Task, err error) {
+	const op = "internal.repository.postgres.task_repo.Update"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
+	// Имена колонок задаются только здесь, значения из патча попадают в запрос исключительно параметрами
 	var setParts []string
 	var args []interface{}
-	i := 1
-
-	for k, v := range updates {
-		setParts = append(setParts, fmt.Sprintf("%s = $%d", k, i))
-		args = append(args, v)
-		i++
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		setParts = append(setParts, fmt.Sprintf("%s = $%d", column, len(args)))
 	}
 
-	args = append(args, taskID)
-	query := fmt.Sprintf("UPDATE tasks SET %s WHERE id = $%d", strings.Join(setParts, ", "), i)
+	if patch.Title != nil {
+		set("title", *patch.Title)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	if patch.Status != nil {
+		set("status", *patch.Status)
+	}
+	if patch.Priority != nil {
+		set("priority", *patch.Priority)
+	}
+	if patch.DueDate != nil {
+		set("due_date", *patch.DueDate)
+	}
+	setParts = append(setParts, "updated_at = NOW()", "version = t.version + 1")
 
-	res, err := r.db.ExecContext(ctx, query, args...)
+	args = append(args, id, ownerID, version)
+	query := fmt.Sprintf(`
+		UPDATE tasks t SET %s
+		FROM (SELECT %s FROM tasks WHERE id = $%d AND owner_id = $%d AND deleted_at IS NULL FOR UPDATE) old
+		WHERE t.id = old.id AND ($%d = 0 OR old.version = $%d)
+		RETURNING %s, %s`,
+		strings.Join(setParts, ", "), taskColumns, len(args)-2, len(args)-1, len(args), len(args),
+		qualifiedTaskColumns("old"), qualifiedTaskColumns("t"))
+
+	var before, task domain.Task
+	err = tx.QueryRowContext(ctx, query, args...).Scan(append(taskFields(&before), taskFields(&task)...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Строка не обновлена: задачи нет или версия устарела. Чтение нужно только для ответа клиенту
+		current, getErr := r.GetByID(ctx, ownerID, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return nil, &domain.VersionConflictError{Current: current}
+	}
 	if err != nil {
 		slog.Error(op, "ошибка обновления задачи", slog.String("err", err.Error()))
-		return fmt.Errorf("не удалось обновить задачу: %w", err)
+		return nil, fmt.Errorf("не удалось обновить задачу: %w", err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		slog.Error(op, "не удалось получить количество затронутых строк", slog.String("err", err.Error()))
-		return fmt.Errorf("не удалось проверить количество затронутых строк: %w", err)
+	if check != nil {
+		if err = check(&before); err != nil {
+			return nil, err
+		}
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("задача с id %v не найдена", taskID)
+	if err = insertEvents(ctx, tx, domain.NewTaskEvent(domain.TaskEventUpdated, actorFromContext(ctx), &before, &task)); err != nil {
+		slog.Error(op, "не удалось записать историю задачи", slog.String("err", err.Error()))
+		return nil, err
 	}
 
-	return nil
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+	return &task, nil
 }
 
-func (r *TaskPostgresRepo) Delete(ctx context.Context, id int64) error {
+// Delete перемещает задачу владельца в корзину. Задача остаётся в базе до Purge или очистки корзины
+func (r *TaskPostgresRepo) Delete(ctx context.Context, ownerID, id int64) (err error) {
 	const op = "internal.repository.postgres.task_repo.Delete"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	query := `DELETE FROM tasks WHERE id = $1`
+	query := `
+		UPDATE tasks SET deleted_at = NOW(), updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND owner_id = $2 AND deleted_at IS NULL
+		RETURNING ` + taskColumns
 
-	res, err := r.db.ExecContext(ctx, query, id)
+	task, err := scanTask(tx.QueryRowContext(ctx, query, id, ownerID))
 	if err != nil {
-		slog.Error(op, "не удалось удалить задачу", slog.String("err", err.Error()))
+		if errors.Is(err, sql.ErrNoRows) {
+			err = domain.NewTaskNotFoundError(id)
+			slog.Error(op, "задача для удаления не найдена", slog.String("err", err.Error()))
+			return err
+		}
+		slog.Error(op, "не удалось переместить задачу в корзину", slog.String("err", err.Error()))
 		return err
 	}
 
-	// Проверка, была ли задача удалена
-	affect, _ := res.RowsAffected()
-	if affect == 0 {
-		err = fmt.Errorf("задача с id %d не найдена для удаления", id)
-		slog.Error(op, "задача для удаления не найдена", slog.String("err", err.Error()))
+	// До удаления задача отличалась только отсутствием deleted_at
+	before := *task
+	before.DeletedAt = nil
+	if err = insertEvents(ctx, tx, domain.NewTaskEvent(domain.TaskEventTrashed, actorFromContext(ctx), &before, task)); err != nil {
+		slog.Error(op, "не удалось записать историю задачи", slog.String("err", err.Error()))
 		return err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
 	return nil
 }
 
-func (r *TaskPostgresRepo) GetAll(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error) {
-	const op = "internal.repository.postgres.task_repo.GetAll"
+// Restore возвращает задачу владельца из корзины
+func (r *TaskPostgresRepo) Restore(ctx context.Context, ownerID, id int64) (_ *domain.Task, err error) {
+	const op = "internal.repository.postgres.task_repo.Restore"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	before, err := scanTask(tx.QueryRowContext(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL FOR UPDATE`, id, ownerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewTaskNotFoundError(id)
+		}
+		slog.Error(op, "не удалось получить задачу из корзины", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, err
+	}
 
 	query := `
-		SELECT id, title, description, status, priority, due_date, created_at, updated_at 
-		FROM tasks 
-	`
+		UPDATE tasks SET deleted_at = NULL, updated_at = NOW(), version = version + 1
+		WHERE id = $1
+		RETURNING ` + taskColumns
 
-	var conditions []string
-	var args []interface{}
-	argIdx := 1
+	task, err := scanTask(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		slog.Error(op, "не удалось восстановить задачу", slog.Int64("id", id), slog.String("err", err.Error()))
+		return nil, err
+	}
 
-	if filter.Status != "" {
-		conditions = append(conditions, fmt.Sprintf("status = $%d", argIdx))
-		args = append(args, filter.Status)
-		argIdx++
+	if err = insertEvents(ctx, tx, domain.NewTaskEvent(domain.TaskEventRestored, actorFromContext(ctx), before, task)); err != nil {
+		slog.Error(op, "не удалось записать историю задачи", slog.String("err", err.Error()))
+		return nil, err
 	}
 
-	if filter.Priority != "" {
-		conditions = append(conditions, fmt.Sprintf("priority = $%d", argIdx))
-		args = append(args, filter.Priority)
-		argIdx++
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
 	}
+	return task, nil
+}
+
+// Purge безвозвратно удаляет задачу владельца, активную или из корзины; снимок задачи сохраняется в истории
+func (r *TaskPostgresRepo) Purge(ctx context.Context, ownerID, id int64) (err error) {
+	const op = "internal.repository.postgres.task_repo.Purge"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
-	if filter.DueDate != "" {
-		conditions = append(conditions, fmt.Sprintf("due_date = $%d", argIdx))
-		args = append(args, filter.DueDate)
-		argIdx++
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	if filter.Title != "" {
-		conditions = append(conditions, fmt.Sprintf("title ILIKE $%d", argIdx))
-		args = append(args, "%"+filter.Title+"%")
-		argIdx++
+	query := `DELETE FROM tasks WHERE id = $1 AND owner_id = $2 RETURNING ` + taskColumns
+
+	task, err := scanTask(tx.QueryRowContext(ctx, query, id, ownerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = domain.NewTaskNotFoundError(id)
+			slog.Error(op, "задача для удаления не найдена", slog.String("err", err.Error()))
+			return err
+		}
+		slog.Error(op, "не удалось удалить задачу", slog.String("err", err.Error()))
+		return err
+	}
+
+	if err = insertEvents(ctx, tx, domain.NewTaskEvent(domain.TaskEventDeleted, actorFromContext(ctx), task, nil)); err != nil {
+		slog.Error(op, "не удалось записать историю задачи", slog.String("err", err.Error()))
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+	return nil
+}
+
+func (r *TaskPostgresRepo) GetAll(ctx context.Context, ownerID int64, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error) {
+	const op = "internal.repository.postgres.task_repo.GetAll"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	conditions, args := buildTaskConditions(ownerID, filter)
+
+	result := &domain.TaskPage{Items: []*domain.Task{}}
+	if !page.SkipTotal {
+		countQuery := `SELECT COUNT(*) FROM tasks WHERE ` + strings.Join(conditions, " AND ")
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+			slog.Error(op, "не удалось посчитать задачи", slog.String("err", err.Error()))
+			return nil, err
+		}
+	}
+
+	sort := page.Sort
+	sortCol, ok := sortColumns[sort.Field]
+	if !ok {
+		sort = domain.DefaultTaskSort
+		sortCol = sortColumns[sort.Field]
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	direction, cmp := "ASC", ">"
+	if sort.Desc {
+		direction, cmp = "DESC", "<"
 	}
 
-	query += " ORDER BY created_at DESC"
+	if page.Cursor != "" {
+		cursor, err := decodeCursor(page.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+			sortCol.expr, cmp, len(args)+1, sortCol.cast, len(args)+2))
+		args = append(args, cursor.Value, cursor.ID)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM tasks WHERE %s ORDER BY %s %s, id %s LIMIT $%d",
+		taskColumns, strings.Join(conditions, " AND "), sortCol.expr, direction, direction, len(args)+1)
+	// Берём на одну задачу больше, чтобы понять, есть ли следующая страница
+	args = append(args, page.Limit+1)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -164,52 +400,270 @@ func (r *TaskPostgresRepo) GetAll(ctx context.Context, filter *domain.TaskFilter
 	}
 	defer rows.Close()
 
-	var tasks []*domain.Task
 	for rows.Next() {
-		var task domain.Task
-		if err = rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		); err != nil {
+		task, err := scanTask(rows)
+		if err != nil {
 			slog.Error(op, "не удалось извлечь данные задачи", slog.String("err", err.Error()))
 			return nil, err
 		}
-		tasks = append(tasks, &task)
+		result.Items = append(result.Items, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "ошибка при переборе строк", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	if len(result.Items) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+		result.NextCursor = encodeCursor(sort, result.Items[page.Limit-1])
+	}
+
+	slog.Debug("получено задач", slog.Int("count", len(result.Items)), slog.Int("total", result.Total))
+
+	return result, nil
+}
+
+// searchQuery tsquery по обеим конфигурациям, из которых собран search_vector
+const searchQuery = `(websearch_to_tsquery('russian', $%[1]d) || websearch_to_tsquery('english', $%[1]d))`
+
+// Search ищет задачи владельца по названию и описанию, упорядочивая их по релевантности
+func (r *TaskPostgresRepo) Search(ctx context.Context, ownerID int64, query string, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskSearchPage, error) {
+	const op = "internal.repository.postgres.task_repo.Search"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	conditions, args := buildTaskConditions(ownerID, filter)
+
+	args = append(args, query)
+	tsQuery := fmt.Sprintf(searchQuery, len(args))
+	rank := fmt.Sprintf("ts_rank(search_vector, %s)", tsQuery)
+	conditions = append(conditions, "search_vector @@ "+tsQuery)
+
+	result := &domain.TaskSearchPage{Items: []*domain.TaskSearchResult{}}
+	if !page.SkipTotal {
+		countQuery := `SELECT COUNT(*) FROM tasks WHERE ` + strings.Join(conditions, " AND ")
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+			slog.Error(op, "не удалось посчитать найденные задачи", slog.String("err", err.Error()))
+			return nil, err
+		}
+	}
+
+	if page.Cursor != "" {
+		cursor, err := decodeCursor(page.Cursor, searchSort)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, id) < ($%d::real, $%d)", rank, len(args)+1, len(args)+2))
+		args = append(args, cursor.Value, cursor.ID)
 	}
 
-	slog.Info("получено задач", slog.Int("count", len(tasks)))
+	// ts_headline считается только для строк текущей страницы, поэтому вынесен во внешний запрос
+	selectQuery := fmt.Sprintf(`
+		SELECT %[1]s, rank,
+		       ts_headline('russian', title || coalesce(' — ' || description, ''), %[2]s,
+		                   'StartSel=<b>, StopSel=</b>, MaxFragments=2, MaxWords=20, MinWords=5')
+		FROM (
+			SELECT %[1]s, %[3]s AS rank FROM tasks
+			WHERE %[4]s
+			ORDER BY rank DESC, id DESC
+			LIMIT $%[5]d
+		) AS found
+		ORDER BY rank DESC, id DESC`,
+		taskColumns, tsQuery, rank, strings.Join(conditions, " AND "), len(args)+1)
+	// Берём на одну задачу больше, чтобы понять, есть ли следующая страница
+	args = append(args, page.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		slog.Error(op, "не удалось выполнить поиск задач", slog.String("err", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &domain.TaskSearchResult{}
+		item.Task, err = scanTask(rows, &item.Rank, &item.Highlight)
+		if err != nil {
+			slog.Error(op, "не удалось извлечь данные задачи", slog.String("err", err.Error()))
+			return nil, err
+		}
+		result.Items = append(result.Items, item)
+	}
 
 	if err = rows.Err(); err != nil {
 		slog.Error(op, "ошибка при переборе строк", slog.String("err", err.Error()))
 		return nil, err
 	}
 
-	return tasks, nil
+	if len(result.Items) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+		result.NextCursor = encodeSearchCursor(result.Items[page.Limit-1])
+	}
+
+	return result, nil
 }
 
-func (r *TaskPostgresRepo) DeleteExpiredTasks(ctx context.Context) (int64, error) {
+// buildTaskConditions собирает условия WHERE для фильтра задач владельца
+func buildTaskConditions(ownerID int64, filter *domain.TaskFilter) ([]string, []interface{}) {
+	conditions := []string{"owner_id = $1", "deleted_at IS NULL"}
+	if filter.InTrash {
+		conditions[1] = "deleted_at IS NOT NULL"
+	}
+	args := []interface{}{ownerID}
+
+	add := func(condition string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i := range values {
+			placeholders[i] = len(args) + i + 1
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+		args = append(args, values...)
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, s := range filter.Statuses {
+			statuses[i] = string(s)
+		}
+		add("status = ANY($%d)", pq.Array(statuses))
+	}
+
+	if len(filter.Priorities) > 0 {
+		priorities := make([]string, len(filter.Priorities))
+		for i, p := range filter.Priorities {
+			priorities[i] = string(p)
+		}
+		add("priority = ANY($%d)", pq.Array(priorities))
+	}
+
+	if filter.DueDate != nil {
+		day := time.Date(filter.DueDate.Year(), filter.DueDate.Month(), filter.DueDate.Day(), 0, 0, 0, 0, filter.DueDate.Location())
+		add("due_date >= $%d AND due_date < $%d", day, day.AddDate(0, 0, 1))
+	}
+
+	if filter.DueBefore != nil {
+		add("due_date < $%d", *filter.DueBefore)
+	}
+
+	if filter.DueAfter != nil {
+		add("due_date > $%d", *filter.DueAfter)
+	}
+
+	if filter.CreatedBefore != nil {
+		add("created_at < $%d", *filter.CreatedBefore)
+	}
+
+	if filter.CreatedAfter != nil {
+		add("created_at > $%d", *filter.CreatedAfter)
+	}
+
+	if filter.Overdue {
+		add("due_date < NOW() AND status <> $%d", string(domain.StatusDone))
+	}
+
+	if filter.Title != "" {
+		add(`title ILIKE $%d ESCAPE '\'`, "%"+escapeLike(filter.Title)+"%")
+	}
+
+	if filter.Description != "" {
+		add(`description ILIKE $%d ESCAPE '\'`, "%"+escapeLike(filter.Description)+"%")
+	}
+
+	return conditions, args
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE, чтобы подстрока из фильтра искалась буквально
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike экранирует %, _ и \ для условия LIKE ... ESCAPE '\'
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// DeleteExpiredTasks перемещает в корзину задачи, срок которых истёк более overdueFor назад.
+// Действие выполняет система, поэтому у событий trashed нет автора
+func (r *TaskPostgresRepo) DeleteExpiredTasks(ctx context.Context, overdueFor time.Duration) (_ int64, err error) {
 	const op = "internal.repository.postgres.task_repo.DeleteExpiredTasks"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
-	query := `DELETE FROM tasks WHERE due_date < NOW() - INTERVAL '7 days'`
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	res, err := r.db.ExecContext(ctx, query)
+	query := `
+		UPDATE tasks SET deleted_at = NOW(), updated_at = NOW(), version = version + 1
+		WHERE due_date < $1 AND deleted_at IS NULL
+		RETURNING ` + taskColumns
+
+	events, err := collectEvents(ctx, tx, query, []interface{}{time.Now().Add(-overdueFor)}, func(task *domain.Task) *domain.TaskEvent {
+		before := *task
+		before.DeletedAt = nil
+		return domain.NewTaskEvent(domain.TaskEventTrashed, nil, &before, task)
+	})
 	if err != nil {
 		slog.Error(op, "не удалось удалить просроченные задачи", slog.String("err", err.Error()))
 		return 0, fmt.Errorf("не удалось удалить просроченные задачи: %w", err)
 	}
 
-	rowsAffected, _ := res.RowsAffected()
-	return rowsAffected, nil
+	if err = insertEvents(ctx, tx, events...); err != nil {
+		slog.Error(op, "не удалось записать историю задач", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+
+	return int64(len(events)), nil
+}
+
+// PurgeTrash безвозвратно удаляет задачи, которые лежат в корзине дольше retention
+func (r *TaskPostgresRepo) PurgeTrash(ctx context.Context, retention time.Duration) (_ int64, err error) {
+	const op = "internal.repository.postgres.task_repo.PurgeTrash"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `DELETE FROM tasks WHERE deleted_at < $1 RETURNING ` + taskColumns
+
+	events, err := collectEvents(ctx, tx, query, []interface{}{time.Now().Add(-retention)}, func(task *domain.Task) *domain.TaskEvent {
+		return domain.NewTaskEvent(domain.TaskEventDeleted, nil, task, nil)
+	})
+	if err != nil {
+		slog.Error(op, "не удалось очистить корзину", slog.String("err", err.Error()))
+		return 0, fmt.Errorf("не удалось очистить корзину: %w", err)
+	}
+
+	if err = insertEvents(ctx, tx, events...); err != nil {
+		slog.Error(op, "не удалось записать историю задач", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+
+	return int64(len(events)), nil
 }
 
 func (r *TaskPostgresRepo) ImportTasks(ctx context.Context, tasks []*domain.Task) (int, error) {
 	const op = "internal.repository.postgres.task_repo.ImportTasks"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -226,22 +680,31 @@ func (r *TaskPostgresRepo) ImportTasks(ctx context.Context, tasks []*domain.Task
 	var args []interface{}
 
 	for i, task := range tasks {
-		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-			i*7+1, i*7+2, i*7+3, i*7+4, i*7+5, i*7+6, i*7+7))
-		args = append(args, task.Title, task.Description, task.Status, task.Priority, task.DueDate, task.CreatedAt, task.UpdatedAt)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*8+1, i*8+2, i*8+3, i*8+4, i*8+5, i*8+6, i*8+7, i*8+8))
+		args = append(args, task.OwnerID, task.Title, task.Description, task.Status, task.Priority, task.DueDate, task.CreatedAt, task.UpdatedAt)
 	}
 
 	query := fmt.Sprintf(`
-        INSERT INTO tasks (title, description, status, priority, due_date, created_at, updated_at)
+        INSERT INTO tasks (owner_id, title, description, status, priority, due_date, created_at, updated_at)
         VALUES %s
-    `, strings.Join(values, ", "))
+        RETURNING %s
+    `, strings.Join(values, ", "), taskColumns)
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	actorID := actorFromContext(ctx)
+	events, err := collectEvents(ctx, tx, query, args, func(task *domain.Task) *domain.TaskEvent {
+		return domain.NewTaskEvent(domain.TaskEventImported, actorID, nil, task)
+	})
 	if err != nil {
 		slog.Error(op, "ошибка импорта задач", slog.String("err", err.Error()))
 		return 0, fmt.Errorf("не удалось импортировать задачи: %w", err)
 	}
 
+	if err = insertEvents(ctx, tx, events...); err != nil {
+		slog.Error(op, "не удалось записать историю задач", slog.String("err", err.Error()))
+		return 0, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return 0, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
 	}
@@ -249,12 +712,13 @@ func (r *TaskPostgresRepo) ImportTasks(ctx context.Context, tasks []*domain.Task
 	return len(values), nil
 }
 
-func (r *TaskPostgresRepo) GetTaskCountByStatus(ctx context.Context) (map[string]int, error) {
-	const op = "internal.repository.postgres.task_repo.GetAnalytics"
+func (r *TaskPostgresRepo) GetTaskCountByStatus(ctx context.Context, ownerID int64) (map[string]int, error) {
+	const op = "internal.repository.postgres.task_repo.GetTaskCountByStatus"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
-	query := `SELECT status, COUNT(*) FROM tasks GROUP BY status`
+	query := `SELECT status, COUNT(*) FROM tasks WHERE owner_id = $1 AND deleted_at IS NULL GROUP BY status`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
 	if err != nil {
 		slog.Error(op, "ошибка выполнения запроса", slog.String("err", err.Error()))
 		return nil, err
@@ -276,80 +740,296 @@ func (r *TaskPostgresRepo) GetTaskCountByStatus(ctx context.Context) (map[string
 	return statusCounts, nil
 }
 
-func (r *TaskPostgresRepo) GetAverageExecutionTime(ctx context.Context) (string, error) {
-	const op = "internal.repository.postgres.task_repo.GetAverageExecutionTime"
+// GetExecutionTimeStats считает lead time и cycle time задач владельца, выполненных в периоде.
+// Задачи без started_at (сразу переведённые в done) учитываются только в lead time
+func (r *TaskPostgresRepo) GetExecutionTimeStats(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ExecutionTimeStats, error) {
+	const op = "internal.repository.postgres.task_repo.GetExecutionTimeStats"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
-	query := `SELECT AVG(EXTRACT(EPOCH FROM (due_date - created_at))) FROM tasks WHERE status = 'done'`
+	query := `
+		SELECT
+			COUNT(lead),
+			AVG(lead),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY lead),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY lead),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY lead),
+			COUNT(cycle),
+			AVG(cycle),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY cycle),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY cycle),
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY cycle)
+		FROM (
+			SELECT
+				EXTRACT(EPOCH FROM completed_at - created_at)::float8 AS lead,
+				EXTRACT(EPOCH FROM completed_at - started_at)::float8 AS cycle
+			FROM tasks
+			WHERE owner_id = $1 AND status = 'done' AND completed_at >= $2 AND completed_at < $3 AND deleted_at IS NULL
+		) AS durations
+	`
 
-	var avgSeconds sql.NullFloat64 // используем sql.NullFloat64 для обработки NULL значений
-	err := r.db.QueryRowContext(ctx, query).Scan(&avgSeconds)
+	var (
+		stats                 domain.ExecutionTimeStats
+		lead, cycle           [4]sql.NullFloat64 // avg, median, p90, p95; NULL, если выполненных задач нет
+		leadCount, cycleCount int
+	)
+	err := r.db.QueryRowContext(ctx, query, ownerID, period.From, period.To).Scan(
+		&leadCount, &lead[0], &lead[1], &lead[2], &lead[3],
+		&cycleCount, &cycle[0], &cycle[1], &cycle[2], &cycle[3],
+	)
 	if err != nil {
-		slog.Error(
-			op,
-			"ошибка выполнения запроса для среднего времени выполнения задач",
-			slog.String("err", err.Error()),
-		)
-		return "", err
+		slog.Error(op, "ошибка выполнения запроса для времени выполнения задач", slog.String("err", err.Error()))
+		return nil, err
 	}
 
-	// Проверяем, что значение не NULL
-	if !avgSeconds.Valid {
-		avgSeconds.Float64 = 0 // если NULL, то ставим 0
-	}
+	stats.LeadTime = durationStats(leadCount, lead)
+	stats.CycleTime = durationStats(cycleCount, cycle)
 
-	avgDuration := time.Duration(avgSeconds.Float64 * float64(time.Second))
-	slog.Info(
-		"успешное получение среднего времени выполнения задач",
-		slog.String("avg_execution_time", avgDuration.String()),
-	)
+	slog.Info("успешное получение времени выполнения задач",
+		slog.Int("done_tasks", leadCount),
+		slog.Float64("lead_time_median", stats.LeadTime.MedianSeconds),
+		slog.Float64("cycle_time_median", stats.CycleTime.MedianSeconds))
 
-	return avgDuration.String(), nil
+	return &stats, nil
 }
 
-func (r *TaskPostgresRepo) GetReportPeriod(ctx context.Context) (*domain.ReportPeriod, error) {
+// durationStats собирает DurationStats из avg, median, p90 и p95; NULL превращается в 0
+func durationStats(count int, values [4]sql.NullFloat64) domain.DurationStats {
+	return domain.DurationStats{
+		Count:          count,
+		AverageSeconds: values[0].Float64,
+		MedianSeconds:  values[1].Float64,
+		P90Seconds:     values[2].Float64,
+		P95Seconds:     values[3].Float64,
+	}
+}
+
+// GetReportPeriod считает задачи, выполненные в периоде, и задачи, срок которых истёк в периоде,
+// а выполнены они не были или были выполнены позже срока
+func (r *TaskPostgresRepo) GetReportPeriod(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ReportPeriod, error) {
 	const op = "internal.repository.postgres.task_repo.GetReportPeriod"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
 	query := `
-		SELECT COUNT(*) 
-		FROM tasks 
-		WHERE updated_at >= NOW() - INTERVAL '7 days' AND status = 'done'
+		SELECT
+			COUNT(*) FILTER (WHERE completed_at >= $2 AND completed_at < $3),
+			COUNT(*) FILTER (WHERE due_date >= $2 AND due_date < $3 AND due_date < NOW()
+			                 AND (completed_at IS NULL OR completed_at > due_date))
+		FROM tasks
+		WHERE owner_id = $1 AND deleted_at IS NULL
 	`
 
-	var completed, overdue int
-	err := r.db.QueryRowContext(ctx, query).Scan(&completed)
+	report := &domain.ReportPeriod{From: period.From, To: period.To}
+	err := r.db.QueryRowContext(ctx, query, ownerID, period.From, period.To).Scan(&report.CompletedTasks, &report.OverdueTasks)
 	if err != nil {
-		slog.Error(
-			op,
-			"ошибка получения количества завершённых задач за последние 7 дней",
-			slog.String("err", err.Error()),
+		slog.Error(op, "ошибка получения отчёта за период", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	slog.Info(op, "успешно получен отчёт за период",
+		slog.Time("from", period.From),
+		slog.Time("to", period.To),
+		slog.Int("completed_tasks", report.CompletedTasks),
+		slog.Int("overdue_tasks", report.OverdueTasks))
+
+	return report, nil
+}
+
+// GetTimeSeries считает созданные, выполненные и просроченные задачи владельца по интервалам bucket.
+// Интервалы без задач возвращаются с нулями, чтобы на графике не было пропусков
+func (r *TaskPostgresRepo) GetTimeSeries(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) ([]domain.TimeSeriesPoint, error) {
+	const op = "internal.repository.postgres.task_repo.GetTimeSeries"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(date_trunc($2::text, $3::timestamptz), $4::timestamptz, ('1 ' || $2::text)::interval) AS bucket
+		),
+		owned AS (
+			SELECT created_at, completed_at, due_date FROM tasks WHERE owner_id = $1 AND deleted_at IS NULL
+		),
+		created AS (
+			SELECT date_trunc($2::text, created_at) AS bucket, COUNT(*) AS n FROM owned
+			WHERE created_at >= $3 AND created_at < $4
+			GROUP BY 1
+		),
+		completed AS (
+			SELECT date_trunc($2::text, completed_at) AS bucket, COUNT(*) AS n FROM owned
+			WHERE completed_at >= $3 AND completed_at < $4
+			GROUP BY 1
+		),
+		overdue AS (
+			SELECT date_trunc($2::text, due_date) AS bucket, COUNT(*) AS n FROM owned
+			WHERE due_date >= $3 AND due_date < $4 AND due_date < NOW()
+			  AND (completed_at IS NULL OR completed_at > due_date)
+			GROUP BY 1
 		)
+		SELECT b.bucket, COALESCE(c.n, 0), COALESCE(d.n, 0), COALESCE(o.n, 0)
+		FROM buckets b
+		LEFT JOIN created c USING (bucket)
+		LEFT JOIN completed d USING (bucket)
+		LEFT JOIN overdue o USING (bucket)
+		WHERE b.bucket < $4
+		ORDER BY b.bucket
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID, string(bucket), period.From, period.To)
+	if err != nil {
+		slog.Error(op, "ошибка получения временного ряда", slog.String("err", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []domain.TimeSeriesPoint{}
+	for rows.Next() {
+		var point domain.TimeSeriesPoint
+		if err = rows.Scan(&point.Start, &point.Created, &point.Completed, &point.Overdue); err != nil {
+			slog.Error(op, "ошибка при сканировании строки", slog.String("err", err.Error()))
+			return nil, err
+		}
+		points = append(points, point)
+	}
 
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "ошибка при переборе строк", slog.String("err", err.Error()))
 		return nil, err
 	}
 
-	query = `
-		SELECT COUNT(*) 
-		FROM tasks 
-		WHERE due_date < NOW() - INTERVAL '7 days' AND status != 'done'
+	return points, nil
+}
+
+// SnapshotDailyCounts записывает количество задач всех владельцев по приоритетам и статусам на день day (UTC).
+// Снимок дня перезаписывается целиком, поэтому повторные запуски в течение дня обновляют его до текущего состояния
+func (r *TaskPostgresRepo) SnapshotDailyCounts(ctx context.Context, day time.Time) (_ int64, err error) {
+	const op = "internal.repository.postgres.task_repo.SnapshotDailyCounts"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	date := day.UTC().Format(time.DateOnly)
+
+	// Удаляем снимок дня, чтобы сочетания, которых больше нет, не остались со старыми значениями
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_daily_snapshots WHERE snapshot_date = $1`, date); err != nil {
+		slog.Error(op, "ошибка удаления снимка дня", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO task_daily_snapshots (snapshot_date, owner_id, priority, status, task_count)
+		SELECT $1::date, owner_id, priority, status, COUNT(*)
+		FROM tasks
+		WHERE owner_id IS NOT NULL AND deleted_at IS NULL
+		GROUP BY owner_id, priority, status
+	`, date)
+	if err != nil {
+		slog.Error(op, "ошибка записи снимка дня", slog.String("err", err.Error()))
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("не удалось получить количество записанных строк: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+
+	return rows, nil
+}
+
+// GetBurndown возвращает остаток и выполненные задачи владельца по дням периода из ежедневных снимков.
+// priorities ограничивает набор задач; пустой список — все задачи. Дни без снимка возвращаются с nil
+func (r *TaskPostgresRepo) GetBurndown(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod, priorities []domain.Priority) ([]domain.BurndownPoint, error) {
+	const op = "internal.repository.postgres.task_repo.GetBurndown"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	query := `
+		WITH days AS (
+			SELECT generate_series($2::date, $3::date, '1 day')::date AS day
+		)
+		SELECT
+			d.day,
+			EXISTS (SELECT 1 FROM task_daily_snapshots e WHERE e.owner_id = $1 AND e.snapshot_date = d.day),
+			COALESCE(SUM(s.task_count) FILTER (WHERE s.status <> 'done'), 0),
+			COALESCE(SUM(s.task_count) FILTER (WHERE s.status = 'done'), 0)
+		FROM days d
+		LEFT JOIN task_daily_snapshots s
+			ON s.owner_id = $1 AND s.snapshot_date = d.day
+			AND (cardinality($4::text[]) = 0 OR s.priority = ANY($4))
+		GROUP BY d.day
+		ORDER BY d.day
 	`
 
-	err = r.db.QueryRowContext(ctx, query).Scan(&overdue)
+	// To не входит в период, поэтому последний день — тот, в который попадает момент перед To
+	lastDay := period.To.Add(-time.Nanosecond).UTC().Format(time.DateOnly)
+	firstDay := period.From.UTC().Format(time.DateOnly)
+
+	filter := make([]string, len(priorities))
+	for i, p := range priorities {
+		filter[i] = string(p)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID, firstDay, lastDay, pq.Array(filter))
 	if err != nil {
-		slog.Error(
-			op,
-			"ошибка получения количества просроченных задач за последние 7 дней",
-			slog.String("err", err.Error()),
+		slog.Error(op, "ошибка получения данных burndown", slog.String("err", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []domain.BurndownPoint{}
+	for rows.Next() {
+		var (
+			point                domain.BurndownPoint
+			found                bool
+			remaining, completed int
 		)
+		if err = rows.Scan(&point.Date, &found, &remaining, &completed); err != nil {
+			slog.Error(op, "ошибка при сканировании строки", slog.String("err", err.Error()))
+			return nil, err
+		}
+		if found {
+			point.Remaining, point.Completed = &remaining, &completed
+		}
+		points = append(points, point)
+	}
 
+	if err = rows.Err(); err != nil {
+		slog.Error(op, "ошибка при переборе строк", slog.String("err", err.Error()))
 		return nil, err
 	}
 
-	slog.Info(op, "успешно получен отчёт за последние 7 дней",
-		slog.Int("completed_tasks", completed),
-		slog.Int("overdue_tasks", overdue))
+	return points, nil
+}
+
+// CountTasks возвращает количество задач всех владельцев (без корзины) по статусу и приоритету для метрик
+func (r *TaskPostgresRepo) CountTasks(ctx context.Context) ([]domain.TaskCount, error) {
+	const op = "internal.repository.postgres.task_repo.CountTasks"
+	defer metrics.ObserveDBQuery(op, time.Now())
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status, priority, COUNT(*) FROM tasks WHERE deleted_at IS NULL GROUP BY status, priority`)
+	if err != nil {
+		slog.Error(op, "ошибка выполнения запроса", slog.String("err", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []domain.TaskCount
+	for rows.Next() {
+		var count domain.TaskCount
+		if err = rows.Scan(&count.Status, &count.Priority, &count.Count); err != nil {
+			slog.Error(op, "ошибка при сканировании строки", slog.String("err", err.Error()))
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
 
-	return &domain.ReportPeriod{
-		CompletedTasks: completed,
-		OverdueTasks:   overdue,
-	}, nil
+	return counts, rows.Err()
 }
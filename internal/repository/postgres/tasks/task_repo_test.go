@@ -19,18 +19,29 @@ func (m *MockTaskPostgresRepo) Create(ctx context.Context, task *domain.Task) er
 	return args.Error(0)
 }
 
-func (m *MockTaskPostgresRepo) Update(ctx context.Context, updates map[string]interface{}) error {
-	args := m.Called(ctx, updates)
-	return args.Error(0)
+func (m *MockTaskPostgresRepo) GetByID(ctx context.Context, ownerID, id int64) (*domain.Task, error) {
+	args := m.Called(ctx, ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskPostgresRepo) Update(ctx context.Context, ownerID int64, updates map[string]interface{}) (*domain.Task, error) {
+	args := m.Called(ctx, ownerID, updates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskPostgresRepo) Delete(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *MockTaskPostgresRepo) Delete(ctx context.Context, ownerID, id int64) error {
+	args := m.Called(ctx, ownerID, id)
 	return args.Error(0)
 }
 
-func (m *MockTaskPostgresRepo) GetAll(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error) {
-	args := m.Called(ctx, filter)
+func (m *MockTaskPostgresRepo) GetAll(ctx context.Context, ownerID int64, filter *domain.TaskFilter) ([]*domain.Task, error) {
+	args := m.Called(ctx, ownerID, filter)
 	return args.Get(0).([]*domain.Task), args.Error(1)
 }
 
@@ -128,12 +139,15 @@ func TestTaskPostgresRepo_Update(t *testing.T) {
 		"title": "Updated Task Title",
 	}
 
-	// Ожидаем, что метод Update будет вызван и вернет nil (успех)
-	mockRepo.On("Update", mock.Anything, updates).Return(nil)
+	stored := &domain.Task{ID: 1, Title: "Updated Task Title"}
 
-	err := mockRepo.Update(context.Background(), updates)
+	// Ожидаем, что метод Update будет вызван и вернет сохранённую задачу
+	mockRepo.On("Update", mock.Anything, int64(1), updates).Return(stored, nil)
+
+	task, err := mockRepo.Update(context.Background(), 1, updates)
 
 	assert.NoError(t, err)
+	assert.Equal(t, stored, task)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -145,9 +159,9 @@ func TestTaskPostgresRepo_Update_Error(t *testing.T) {
 	}
 
 	// Настроим мок, чтобы метод Update вернул ошибку
-	mockRepo.On("Update", mock.Anything, updates).Return(assert.AnError)
+	mockRepo.On("Update", mock.Anything, int64(1), updates).Return(nil, assert.AnError)
 
-	err := mockRepo.Update(context.Background(), updates)
+	_, err := mockRepo.Update(context.Background(), 1, updates)
 
 	// Проверяем, что ошибка произошла
 	assert.Error(t, err)
@@ -159,9 +173,9 @@ func TestTaskPostgresRepo_Delete(t *testing.T) {
 	mockRepo := new(MockTaskPostgresRepo)
 
 	// Ожидаем, что метод Delete будет вызван с id 1 и вернет nil (успех)
-	mockRepo.On("Delete", mock.Anything, int64(1)).Return(nil)
+	mockRepo.On("Delete", mock.Anything, int64(1), int64(1)).Return(nil)
 
-	err := mockRepo.Delete(context.Background(), 1)
+	err := mockRepo.Delete(context.Background(), 1, 1)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -171,9 +185,9 @@ func TestTaskPostgresRepo_Delete_Error(t *testing.T) {
 	mockRepo := new(MockTaskPostgresRepo)
 
 	// Настроим мок, чтобы метод Delete вернул ошибку
-	mockRepo.On("Delete", mock.Anything, int64(1)).Return(assert.AnError)
+	mockRepo.On("Delete", mock.Anything, int64(1), int64(1)).Return(assert.AnError)
 
-	err := mockRepo.Delete(context.Background(), 1)
+	err := mockRepo.Delete(context.Background(), 1, 1)
 
 	// Проверяем, что ошибка произошла
 	assert.Error(t, err)
@@ -192,9 +206,9 @@ func TestTaskPostgresRepo_GetAll(t *testing.T) {
 	}
 
 	// Ожидаем, что метод GetAll вернет список задач
-	mockRepo.On("GetAll", mock.Anything, filter).Return(tasks, nil)
+	mockRepo.On("GetAll", mock.Anything, int64(1), filter).Return(tasks, nil)
 
-	result, err := mockRepo.GetAll(context.Background(), filter)
+	result, err := mockRepo.GetAll(context.Background(), 1, filter)
 
 	assert.NoError(t, err)
 	assert.Equal(t, tasks, result)
@@ -208,10 +222,10 @@ func TestTaskPostgresRepo_GetAll_Error(t *testing.T) {
 	filter := &domain.TaskFilter{}
 
 	// Настроим мок, чтобы метод GetAll вернул пустой срез и ошибку
-	mockRepo.On("GetAll", mock.Anything, filter).Return([]*domain.Task{}, assert.AnError)
+	mockRepo.On("GetAll", mock.Anything, int64(1), filter).Return([]*domain.Task{}, assert.AnError)
 
 	// Выполняем метод GetAll
-	result, err := mockRepo.GetAll(context.Background(), filter)
+	result, err := mockRepo.GetAll(context.Background(), 1, filter)
 
 	// Проверяем, что ошибка произошла
 	assert.Error(t, err)
@@ -226,15 +240,15 @@ func TestTaskPostgresRepo_GetAll_FilterByStatus(t *testing.T) {
 	mockRepo := new(MockTaskPostgresRepo)
 
 	// Создаем фильтр для задач со статусом "pending"
-	filter := &domain.TaskFilter{Status: string(domain.StatusPending)}
+	filter := &domain.TaskFilter{Statuses: []domain.Status{domain.StatusPending}}
 	tasks := []*domain.Task{
 		{Title: "Task 1", Status: domain.StatusPending},
 	}
 
 	// Ожидаем, что метод GetAll вернет только задачи со статусом "pending"
-	mockRepo.On("GetAll", mock.Anything, filter).Return(tasks, nil)
+	mockRepo.On("GetAll", mock.Anything, int64(1), filter).Return(tasks, nil)
 
-	result, err := mockRepo.GetAll(context.Background(), filter)
+	result, err := mockRepo.GetAll(context.Background(), 1, filter)
 
 	assert.NoError(t, err)
 	assert.Equal(t, tasks, result)
@@ -279,3 +293,11 @@ func TestTaskPostgresRepo_ImportTasks_Error(t *testing.T) {
 	assert.Equal(t, 0, count)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestBuildTaskConditions_EscapesLikePattern(t *testing.T) {
+	conditions, args := buildTaskConditions(1, &domain.TaskFilter{Title: `100%_done`, Description: `C:\tmp`})
+
+	assert.Contains(t, conditions, `title ILIKE $2 ESCAPE '\'`)
+	assert.Contains(t, conditions, `description ILIKE $3 ESCAPE '\'`)
+	assert.Equal(t, []interface{}{int64(1), `%100\%\_done%`, `%C:\\tmp%`}, args)
+}
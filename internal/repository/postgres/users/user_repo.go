@@ -2,12 +2,14 @@ package users
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/metrics"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/lib/pq"
 	"log/slog"
+	"time"
 )
 
 type UserPostgresRepo struct {
@@ -23,6 +25,7 @@ func NewUserPostgresRepo(db *sql.DB) *UserPostgresRepo {
 // Create создает нового пользователя в базе данных
 func (r *UserPostgresRepo) Create(ctx context.Context, user *domain.User) error {
 	const op = "internal.repository.postgres.user_repo.Create"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
 	query := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3)`
 
@@ -30,7 +33,7 @@ func (r *UserPostgresRepo) Create(ctx context.Context, user *domain.User) error
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
-			return fmt.Errorf("%w", errors.New("email уже используется"))
+			return domain.ErrEmailTaken
 		}
 
 		slog.Error(op,
@@ -47,6 +50,7 @@ func (r *UserPostgresRepo) Create(ctx context.Context, user *domain.User) error
 // FindByEmail находит пользователя по email в базе данных
 func (r *UserPostgresRepo) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	const op = "internal.repository.postgres.user_repo.FindByEmail"
+	defer metrics.ObserveDBQuery(op, time.Now())
 
 	query := `SELECT id, username, email, password_hash FROM users WHERE email = $1`
 
@@ -56,7 +60,7 @@ func (r *UserPostgresRepo) FindByEmail(ctx context.Context, email string) (*doma
 	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("пользователь не найден")
+			return nil, domain.ErrUserNotFound
 		}
 
 		slog.Error(op,
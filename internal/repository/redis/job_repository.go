@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"GoTasker/internal/config"
+	"GoTasker/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"time"
+)
+
+const (
+	jobLeaseKey   = "job_lease:%s:%d" // Аренда одного запуска: имя задачи и плановое время запуска
+	jobLastRunKey = "job_last_run:%s"
+)
+
+// JobRedisRepo общее для реплик состояние планировщика фоновых задач
+type JobRedisRepo struct {
+	client *redis.Client
+	cfg    *config.Config
+}
+
+func NewJobRedisRepo(cfg *config.Config) *JobRedisRepo {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	return &JobRedisRepo{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Close закрывает соединения с Redis
+func (r *JobRedisRepo) Close() error {
+	return r.client.Close()
+}
+
+// AcquireLease берёт аренду запуска задачи job, запланированного на tick. Аренду получает только первая реплика;
+// она не снимается после выполнения и истекает через ttl, поэтому этот запуск никто не повторит
+func (r *JobRedisRepo) AcquireLease(ctx context.Context, job string, tick time.Time, ttl time.Duration) (bool, error) {
+	const op = "internal.repository.redis.AcquireLease"
+
+	ok, err := r.client.SetNX(ctx, fmt.Sprintf(jobLeaseKey, job, tick.Unix()), "1", ttl).Result()
+	if err != nil {
+		slog.Error(op, "ошибка получения аренды запуска", slog.String("err", err.Error()))
+		return false, fmt.Errorf("ошибка получения аренды запуска: %w", err)
+	}
+
+	return ok, nil
+}
+
+// SaveRun сохраняет результат последнего запуска задачи
+func (r *JobRedisRepo) SaveRun(ctx context.Context, job string, run *domain.JobRun) error {
+	const op = "internal.repository.redis.SaveRun"
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации данных: %w", err)
+	}
+
+	if err = r.client.Set(ctx, fmt.Sprintf(jobLastRunKey, job), data, 0).Err(); err != nil {
+		slog.Error(op, "ошибка сохранения результата запуска", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка сохранения результата запуска: %w", err)
+	}
+
+	return nil
+}
+
+// LastRun возвращает результат последнего запуска задачи или nil, если она не запускалась
+func (r *JobRedisRepo) LastRun(ctx context.Context, job string) (*domain.JobRun, error) {
+	const op = "internal.repository.redis.LastRun"
+
+	val, err := r.client.Get(ctx, fmt.Sprintf(jobLastRunKey, job)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	} else if err != nil {
+		slog.Error(op, "ошибка получения результата запуска", slog.String("err", err.Error()))
+		return nil, fmt.Errorf("ошибка получения результата запуска: %w", err)
+	}
+
+	var run domain.JobRun
+	if err = json.Unmarshal(val, &run); err != nil {
+		return nil, fmt.Errorf("ошибка десериализации данных: %w", err)
+	}
+
+	return &run, nil
+}
@@ -4,13 +4,36 @@ import (
 	"GoTasker/internal/config"
 	"GoTasker/internal/domain"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"log/slog"
+	"time"
 )
 
+// Ключи кэша аналитики содержат пользователя, версию его данных и параметры запроса (период, размер интервала).
+// Версия складывается из счётчиков изменений: после инкремента старые записи перестают читаться и истекают по TTL.
+// Префикс v1 — версия формата записи; её меняют, если закэшированные структуры становятся несовместимы
+const (
+	analyticsCacheKey     = "analytics_cache:v1:%d:%s:%s"
+	timeSeriesCacheKey    = "analytics_timeseries:v1:%d:%s:%s:%s"
+	analyticsGenKey       = "analytics_gen:%d"
+	analyticsGlobalGenKey = "analytics_gen:all" // Меняется при изменении задач многих пользователей сразу
+	analyticsLockKey      = "analytics_lock:%s"
+)
+
+// unlockScript снимает блокировку, только если её держит владелец token,
+// чтобы не снять чужую блокировку после истечения своей
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
 type AnalyticsRedisRepo struct {
 	client *redis.Client
 	cfg    *config.Config
@@ -29,36 +52,146 @@ func NewAnalyticsRedisRepo(cfg *config.Config) *AnalyticsRedisRepo {
 	}
 }
 
-func (r *AnalyticsRedisRepo) GetAnalytics(ctx context.Context) (*domain.AnalyticsTasksResponse, error) {
+// Close закрывает соединения с Redis
+func (r *AnalyticsRedisRepo) Close() error {
+	return r.client.Close()
+}
+
+// CacheVersion возвращает текущую версию данных аналитики пользователя для ключей кэша
+func (r *AnalyticsRedisRepo) CacheVersion(ctx context.Context, ownerID int64) (string, error) {
+	const op = "internal.repository.redis.CacheVersion"
+
+	values, err := r.client.MGet(ctx, analyticsGlobalGenKey, fmt.Sprintf(analyticsGenKey, ownerID)).Result()
+	if err != nil {
+		slog.Error(op, "ошибка получения версии кэша", slog.String("err", err.Error()))
+		return "", fmt.Errorf("ошибка получения версии кэша: %w", err)
+	}
+
+	gens := make([]interface{}, len(values))
+	for i, v := range values {
+		if v == nil {
+			v = "0"
+		}
+		gens[i] = v
+	}
+
+	return fmt.Sprintf("%v.%v", gens...), nil
+}
+
+// InvalidateAnalytics меняет версию данных аналитики пользователя; ownerID == 0 — всех пользователей
+func (r *AnalyticsRedisRepo) InvalidateAnalytics(ctx context.Context, ownerID int64) error {
+	const op = "internal.repository.redis.InvalidateAnalytics"
+
+	key := analyticsGlobalGenKey
+	if ownerID != 0 {
+		key = fmt.Sprintf(analyticsGenKey, ownerID)
+	}
+
+	if err := r.client.Incr(ctx, key).Err(); err != nil {
+		slog.Error(op, "ошибка сброса кэша аналитики", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка сброса кэша аналитики: %w", err)
+	}
+
+	return nil
+}
+
+// TryLock захватывает блокировку name на ttl. Возвращает токен для Unlock или пустую строку, если блокировка занята
+func (r *AnalyticsRedisRepo) TryLock(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	const op = "internal.repository.redis.TryLock"
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка генерации токена блокировки: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	ok, err := r.client.SetNX(ctx, fmt.Sprintf(analyticsLockKey, name), token, ttl).Result()
+	if err != nil {
+		slog.Error(op, "ошибка захвата блокировки", slog.String("err", err.Error()))
+		return "", fmt.Errorf("ошибка захвата блокировки: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+
+	return token, nil
+}
+
+// Unlock снимает блокировку name, если она всё ещё принадлежит token
+func (r *AnalyticsRedisRepo) Unlock(ctx context.Context, name, token string) error {
+	const op = "internal.repository.redis.Unlock"
+
+	if err := unlockScript.Run(ctx, r.client, []string{fmt.Sprintf(analyticsLockKey, name)}, token).Err(); err != nil {
+		slog.Error(op, "ошибка снятия блокировки", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка снятия блокировки: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AnalyticsRedisRepo) GetAnalytics(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error) {
 	const op = "internal.repository.redis.GetAnalytics"
 
-	val, err := r.client.Get(ctx, "analytics_cache").Result()
+	var analytics domain.AnalyticsTasksResponse
+	found, err := r.getJSON(ctx, op, fmt.Sprintf(analyticsCacheKey, ownerID, version, period.CacheKey()), &analytics)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &analytics, nil
+}
+
+func (r *AnalyticsRedisRepo) SetAnalytics(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, analytics *domain.AnalyticsTasksResponse) error {
+	const op = "internal.repository.redis.SetAnalytics"
+
+	return r.setJSON(ctx, op, fmt.Sprintf(analyticsCacheKey, ownerID, version, period.CacheKey()), analytics)
+}
+
+func (r *AnalyticsRedisRepo) GetTimeSeries(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) (*domain.TimeSeries, error) {
+	const op = "internal.repository.redis.GetTimeSeries"
+
+	var series domain.TimeSeries
+	found, err := r.getJSON(ctx, op, fmt.Sprintf(timeSeriesCacheKey, ownerID, version, bucket, period.CacheKey()), &series)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+func (r *AnalyticsRedisRepo) SetTimeSeries(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, series *domain.TimeSeries) error {
+	const op = "internal.repository.redis.SetTimeSeries"
+
+	return r.setJSON(ctx, op, fmt.Sprintf(timeSeriesCacheKey, ownerID, version, series.Bucket, period.CacheKey()), series)
+}
+
+// getJSON читает значение из кэша в dest; found == false, если ключа нет
+func (r *AnalyticsRedisRepo) getJSON(ctx context.Context, op, key string, dest interface{}) (bool, error) {
+	val, err := r.client.Get(ctx, key).Result()
 	if errors.Is(err, redis.Nil) {
-		return nil, nil
+		return false, nil
 	} else if err != nil {
 		slog.Error(op, "ошибка получения данных из Redis", slog.String("err", err.Error()))
-		return nil, fmt.Errorf("ошибка получения данных из Redis: %w", err)
+		return false, fmt.Errorf("ошибка получения данных из Redis: %w", err)
 	}
 
-	var analytics domain.AnalyticsTasksResponse
-	if err = json.Unmarshal([]byte(val), &analytics); err != nil {
+	if err = json.Unmarshal([]byte(val), dest); err != nil {
 		slog.Error(op, "ошибка десериализации данных", slog.String("err", err.Error()))
-		return nil, fmt.Errorf("ошибка десериализации данных: %w", err)
+		return false, fmt.Errorf("ошибка десериализации данных: %w", err)
 	}
 
-	return &analytics, nil
+	return true, nil
 }
 
-func (r *AnalyticsRedisRepo) SetAnalytics(ctx context.Context, analytics *domain.AnalyticsTasksResponse) error {
-	const op = "internal.repository.redis.SetAnalytics"
-
-	data, err := json.Marshal(analytics)
+// setJSON сохраняет значение в кэш на cfg.Redis.TTL
+func (r *AnalyticsRedisRepo) setJSON(ctx context.Context, op, key string, value interface{}) error {
+	data, err := json.Marshal(value)
 	if err != nil {
 		slog.Error(op, "ошибка сериализации данных", slog.String("err", err.Error()))
 		return fmt.Errorf("ошибка сериализации данных: %w", err)
 	}
 
-	err = r.client.Set(ctx, "analytics_cache", string(data), r.cfg.Redis.TTL).Err()
+	err = r.client.Set(ctx, key, string(data), r.cfg.Redis.TTL).Err()
 	if err != nil {
 		slog.Error(op, "ошибка сохранения данных в Redis", slog.String("err", err.Error()))
 		return fmt.Errorf("ошибка сохранения данных в Redis: %w", err)
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"GoTasker/internal/config"
+	"GoTasker/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+const (
+	refreshFamilyKey     = "refresh_family:%s"
+	revokedTokenKey      = "revoked_token:%s"
+	userRevokedBeforeKey = "user_revoked_before:%d"
+)
+
+// rotateRefreshScript атомарно заменяет текущий refresh токен семейства.
+// Возвращает 1 при успешной ротации, 0 если семейство не найдено,
+// -1 если предъявлен уже использованный токен (семейство при этом удаляется).
+var rotateRefreshScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return -1
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+type TokenRedisRepo struct {
+	client *redis.Client
+	cfg    *config.Config
+}
+
+func NewTokenRedisRepo(cfg *config.Config) *TokenRedisRepo {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	return &TokenRedisRepo{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Close закрывает соединения с Redis
+func (r *TokenRedisRepo) Close() error {
+	return r.client.Close()
+}
+
+// Ping проверяет соединение с Redis, через которое проверяется отзыв токенов
+func (r *TokenRedisRepo) Ping(ctx context.Context) error {
+	const op = "internal.repository.redis.Ping"
+
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		slog.Error(op, "Redis недоступен", slog.String("err", err.Error()))
+		return fmt.Errorf("Redis недоступен: %w", err)
+	}
+
+	return nil
+}
+
+// SaveRefreshFamily заводит новое семейство refresh токенов с текущим токеном tokenID
+func (r *TokenRedisRepo) SaveRefreshFamily(ctx context.Context, familyID, tokenID string, ttl time.Duration) error {
+	const op = "internal.repository.redis.SaveRefreshFamily"
+
+	err := r.client.Set(ctx, fmt.Sprintf(refreshFamilyKey, familyID), tokenID, ttl).Err()
+	if err != nil {
+		slog.Error(op, "ошибка сохранения семейства токенов", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка сохранения семейства токенов: %w", err)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken заменяет текущий токен семейства oldID на newID.
+// Если oldID уже был использован, всё семейство отзывается.
+func (r *TokenRedisRepo) RotateRefreshToken(ctx context.Context, familyID, oldID, newID string, ttl time.Duration) error {
+	const op = "internal.repository.redis.RotateRefreshToken"
+
+	key := fmt.Sprintf(refreshFamilyKey, familyID)
+	res, err := rotateRefreshScript.Run(ctx, r.client, []string{key}, oldID, newID, ttl.Milliseconds()).Int()
+	if err != nil {
+		slog.Error(op, "ошибка ротации refresh токена", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка ротации refresh токена: %w", err)
+	}
+
+	switch res {
+	case 0:
+		return domain.ErrSessionRevoked
+	case -1:
+		slog.Warn(op, "повторное использование refresh токена, семейство отозвано",
+			slog.String("family_id", familyID))
+		return domain.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+// RevokeRefreshFamily отзывает все refresh токены семейства
+func (r *TokenRedisRepo) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	const op = "internal.repository.redis.RevokeRefreshFamily"
+
+	if err := r.client.Del(ctx, fmt.Sprintf(refreshFamilyKey, familyID)).Err(); err != nil {
+		slog.Error(op, "ошибка отзыва семейства токенов", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка отзыва семейства токенов: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeToken добавляет токен в deny-list до момента его истечения
+func (r *TokenRedisRepo) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	const op = "internal.repository.redis.RevokeToken"
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := r.client.Set(ctx, fmt.Sprintf(revokedTokenKey, tokenID), 1, ttl).Err(); err != nil {
+		slog.Error(op, "ошибка отзыва токена", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка отзыва токена: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserTokens отзывает все токены пользователя, выпущенные раньше момента at (с точностью до миллисекунд),
+// чтобы токены повторного входа сразу после отзыва оставались действительными.
+// Метка живёт столько же, сколько самый долгоживущий токен.
+func (r *TokenRedisRepo) RevokeUserTokens(ctx context.Context, userID int64, at time.Time) error {
+	const op = "internal.repository.redis.RevokeUserTokens"
+
+	key := fmt.Sprintf(userRevokedBeforeKey, userID)
+	if err := r.client.Set(ctx, key, at.UnixMilli(), r.cfg.Server.RefreshDuration).Err(); err != nil {
+		slog.Error(op, "ошибка отзыва токенов пользователя", slog.String("err", err.Error()))
+		return fmt.Errorf("ошибка отзыва токенов пользователя: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked проверяет, отозван ли токен лично или вместе со всеми токенами пользователя
+func (r *TokenRedisRepo) IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error) {
+	const op = "internal.repository.redis.IsTokenRevoked"
+
+	pipe := r.client.Pipeline()
+	revoked := pipe.Exists(ctx, fmt.Sprintf(revokedTokenKey, tokenID))
+	revokedBefore := pipe.Get(ctx, fmt.Sprintf(userRevokedBeforeKey, userID))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		slog.Error(op, "ошибка проверки отзыва токена", slog.String("err", err.Error()))
+		return false, fmt.Errorf("ошибка проверки отзыва токена: %w", err)
+	}
+
+	if revoked.Val() > 0 {
+		return true, nil
+	}
+
+	if val := revokedBefore.Val(); val != "" {
+		before, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			slog.Error(op, "некорректная метка отзыва токенов", slog.String("err", err.Error()))
+			return false, fmt.Errorf("некорректная метка отзыва токенов: %w", err)
+		}
+		if issuedBefore(issuedAt, before) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// issuedBefore проверяет, выпущен ли токен раньше метки отзыва before (Unix-время в миллисекундах)
+func issuedBefore(issuedAt time.Time, before int64) bool {
+	return issuedAt.UnixMilli() < before
+}
@@ -0,0 +1,16 @@
+package redis
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIssuedBefore(t *testing.T) {
+	revokedAt := time.Date(2025, 3, 1, 10, 0, 0, 400*int(time.Millisecond), time.UTC)
+	before := revokedAt.UnixMilli()
+
+	assert.True(t, issuedBefore(revokedAt.Add(-time.Millisecond), before), "токен, выпущенный до отзыва, отозван")
+	assert.False(t, issuedBefore(revokedAt, before), "токен повторного входа в ту же миллисекунду действителен")
+	assert.False(t, issuedBefore(revokedAt.Add(300*time.Millisecond), before), "токен повторного входа в ту же секунду действителен")
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField допустимые значения одного поля cron-выражения
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"минуты", 0, 59},
+	{"часы", 0, 23},
+	{"день месяца", 1, 31},
+	{"месяц", 1, 12},
+	{"день недели", 0, 7}, // 0 и 7 — воскресенье
+}
+
+// cronAliases сокращённые расписания
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// maxSearchYears ограничивает поиск следующего запуска для невыполнимых расписаний вроде 30 февраля
+const maxSearchYears = 5
+
+// Schedule расписание в формате cron из пяти полей: минуты, часы, день месяца, месяц, день недели.
+// Поле принимает *, число, диапазон a-b, список через запятую и шаг /n. Время считается в UTC
+type Schedule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64 // Битовые маски допустимых значений
+	domRestricted, dowRestricted  bool   // Поле задано не как *; влияет на сочетание дня месяца и дня недели
+}
+
+// ParseSchedule разбирает cron-выражение или одно из сокращений @hourly, @daily, @midnight, @weekly, @monthly
+func ParseSchedule(spec string) (*Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if alias, ok := cronAliases[expr]; ok {
+		expr = alias
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("расписание %q: ожидается 5 полей, получено %d", spec, len(parts))
+	}
+
+	var masks [5]uint64
+	for i, part := range parts {
+		mask, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("расписание %q: %w", spec, err)
+		}
+		masks[i] = mask
+	}
+
+	// Воскресенье можно записать как 0 или 7
+	if masks[4]&(1<<7) != 0 {
+		masks[4] = masks[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		spec:          strings.TrimSpace(spec),
+		minute:        masks[0],
+		hour:          masks[1],
+		dom:           masks[2],
+		month:         masks[3],
+		dow:           masks[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+// parseCronField разбирает одно поле в битовую маску
+func parseCronField(value string, field cronField) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, step := item, 1
+		if before, after, ok := strings.Cut(item, "/"); ok {
+			n, err := strconv.Atoi(after)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("поле %s: некорректный шаг %q", field.name, after)
+			}
+			rangePart, step = before, n
+		}
+
+		lo, hi := field.min, field.max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("поле %s: некорректное значение %q", field.name, item)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("поле %s: некорректное значение %q", field.name, item)
+				}
+			} else if step > 1 {
+				// a/n означает «от a до конца с шагом n»
+				hi = field.max
+			}
+		}
+
+		if lo < field.min || hi > field.max || lo > hi {
+			return 0, fmt.Errorf("поле %s: значение %q вне диапазона %d-%d", field.name, item, field.min, field.max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+
+	return mask, nil
+}
+
+// Next возвращает ближайший момент запуска строго после t (с точностью до минуты, UTC).
+// Для невыполнимых расписаний (например, 30 февраля) возвращает нулевое время
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches проверяет день. Как в классическом cron, если заданы и день месяца, и день недели,
+// достаточно совпадения любого из них
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// String возвращает исходное выражение расписания
+func (s *Schedule) String() string {
+	return s.spec
+}
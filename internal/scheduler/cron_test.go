@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// Суббота
+	now := time.Date(2025, 3, 1, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 1, 10, 18, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 3, 2, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2025, 3, 1, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1,7 *", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// День месяца и день недели вместе — достаточно любого совпадения
+		{"0 0 15 * 1", time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(now))
+		})
+	}
+
+	t.Run("невыполнимое расписание", func(t *testing.T) {
+		schedule, err := ParseSchedule("0 0 30 2 *")
+		require.NoError(t, err)
+		assert.True(t, schedule.Next(now).IsZero())
+	})
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@yearly"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseSchedule(spec)
+			assert.Error(t, err)
+		})
+	}
+}
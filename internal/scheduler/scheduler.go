@@ -0,0 +1,282 @@
+package scheduler
+
+import (
+	"GoTasker/internal/domain"
+	"GoTasker/internal/metrics"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// minLeaseTTL нижняя граница аренды запуска, чтобы часы реплик, расходящиеся на секунды,
+// не позволили второй реплике взять тот же запуск
+const minLeaseTTL = time.Minute
+
+// overlapCheckPeriod на сколько вперёд New проверяет интервалы расписания: за год повторяется
+// любое расписание, кроме привязанных к 29 февраля
+const overlapCheckPeriod = 366 * 24 * time.Hour
+
+// storeTimeout ограничивает запись результата запуска, которая идёт и после отмены контекста планировщика
+const storeTimeout = 5 * time.Second
+
+// RunFunc выполняет фоновую задачу и возвращает количество обработанных строк
+type RunFunc func(ctx context.Context) (int64, error)
+
+// Job фоновая задача с расписанием
+type Job struct {
+	Name     string
+	Schedule *Schedule
+	Timeout  time.Duration // Таймаут одной попытки
+	Retries  int           // Повторные попытки после ошибки
+	Backoff  time.Duration // Пауза перед первой повторной попыткой, дальше удваивается
+	Run      RunFunc
+}
+
+// maxDuration наибольшая длительность запуска: все попытки до таймаута и паузы между ними
+func (j Job) maxDuration() time.Duration {
+	d := j.Timeout * time.Duration(j.Retries+1)
+	backoff := j.Backoff
+	for i := 0; i < j.Retries; i++ {
+		d += backoff
+		backoff *= 2
+	}
+	return d
+}
+
+// shortestInterval ищет среди запусков расписания в ближайшие overlapCheckPeriod интервал не длиннее d
+func shortestInterval(schedule *Schedule, from time.Time, d time.Duration) (time.Duration, bool) {
+	end := from.Add(overlapCheckPeriod)
+	prev := schedule.Next(from)
+	for !prev.IsZero() && prev.Before(end) {
+		next := schedule.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if interval := next.Sub(prev); interval <= d {
+			return interval, true
+		}
+		prev = next
+	}
+	return 0, false
+}
+
+// Store общее для реплик хранилище аренды запусков и их результатов
+type Store interface {
+	AcquireLease(ctx context.Context, job string, tick time.Time, ttl time.Duration) (bool, error)
+	SaveRun(ctx context.Context, job string, run *domain.JobRun) error
+	LastRun(ctx context.Context, job string) (*domain.JobRun, error)
+}
+
+// Scheduler запускает задачи по расписанию. Каждый запуск выполняет одна реплика — та,
+// что первой взяла аренду этого запуска в Store
+type Scheduler struct {
+	store    Store
+	instance string
+	jobs     []Job
+	now      func() time.Time
+
+	mu      sync.Mutex
+	nextRun map[string]time.Time
+	lastRun map[string]*domain.JobRun // Локальная копия на случай недоступности Store
+}
+
+// New создаёт планировщик; instance — имя реплики для журнала запусков (например, hostname)
+func New(store Store, instance string, jobs ...Job) (*Scheduler, error) {
+	names := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		switch {
+		case job.Name == "":
+			return nil, errors.New("у фоновой задачи не задано имя")
+		case names[job.Name]:
+			return nil, fmt.Errorf("фоновая задача %s указана дважды", job.Name)
+		case job.Schedule == nil || job.Run == nil:
+			return nil, fmt.Errorf("у фоновой задачи %s не задано расписание или функция запуска", job.Name)
+		case job.Timeout <= 0 || job.Retries < 0 || job.Backoff < 0:
+			return nil, fmt.Errorf("у фоновой задачи %s некорректные таймаут или повторы", job.Name)
+		}
+		// Аренда запуска не мешает другой реплике взять следующий, поэтому запуск обязан завершиться до него
+		if interval, ok := shortestInterval(job.Schedule, time.Now(), job.maxDuration()); ok {
+			return nil, fmt.Errorf("фоновая задача %s с повторами может выполняться до %s, а между её запусками бывает %s: уменьшите таймаут, повторы или паузу",
+				job.Name, job.maxDuration(), interval)
+		}
+		names[job.Name] = true
+	}
+
+	return &Scheduler{
+		store:    store,
+		instance: instance,
+		jobs:     jobs,
+		now:      time.Now,
+		nextRun:  make(map[string]time.Time, len(jobs)),
+		lastRun:  make(map[string]*domain.JobRun, len(jobs)),
+	}, nil
+}
+
+// Run запускает задачи по расписанию и блокируется до отмены ctx и завершения начатых запусков
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// loop ждёт очередного времени запуска задачи и выполняет её
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	const op = "internal.scheduler.loop"
+
+	for {
+		next := job.Schedule.Next(s.now())
+		if next.IsZero() {
+			slog.Error(op, "у расписания нет ближайших запусков", slog.String("job", job.Name), slog.String("schedule", job.Schedule.String()))
+			return
+		}
+		s.setNextRun(job.Name, next)
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runTick(ctx, job, next)
+	}
+}
+
+// runTick выполняет запуск tick, если эта реплика первой взяла его аренду
+func (s *Scheduler) runTick(ctx context.Context, job Job, tick time.Time) {
+	const op = "internal.scheduler.runTick"
+
+	// Аренда живёт до следующего запуска: реплика, опоздавшая на этот, не выполнит его повторно
+	ttl := job.Schedule.Next(tick).Sub(tick)
+	if ttl < minLeaseTTL {
+		ttl = minLeaseTTL
+	}
+
+	acquired, err := s.store.AcquireLease(ctx, job.Name, tick, ttl)
+	if err != nil {
+		// Без аренды нельзя гарантировать единственный запуск, поэтому пропускаем его
+		slog.Error(op, "не удалось взять аренду запуска, запуск пропущен", slog.String("job", job.Name), slog.String("err", err.Error()))
+		return
+	}
+	if !acquired {
+		slog.Debug(op, "запуск выполняет другая реплика", slog.String("job", job.Name), slog.Time("tick", tick))
+		return
+	}
+
+	run := s.execute(ctx, job)
+
+	if run.Status == domain.JobRunSuccess {
+		slog.Info("фоновая задача выполнена", slog.String("job", job.Name), slog.Int64("rows", run.Rows), slog.Int("attempts", run.Attempts))
+	} else {
+		slog.Error(op, "фоновая задача завершилась ошибкой", slog.String("job", job.Name), slog.Int("attempts", run.Attempts), slog.String("err", run.Error))
+	}
+
+	s.mu.Lock()
+	s.lastRun[job.Name] = run
+	s.mu.Unlock()
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+	if err = s.store.SaveRun(storeCtx, job.Name, run); err != nil {
+		slog.Error(op, "не удалось сохранить результат запуска", slog.String("job", job.Name), slog.String("err", err.Error()))
+	}
+}
+
+// execute выполняет задачу с таймаутом на попытку и повторами с экспоненциальной паузой
+func (s *Scheduler) execute(ctx context.Context, job Job) *domain.JobRun {
+	run := &domain.JobRun{StartedAt: s.now(), Instance: s.instance}
+
+	var (
+		rows    int64
+		err     error
+		backoff = job.Backoff
+	)
+	for run.Attempts = 1; ; run.Attempts++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+		rows, err = job.Run(attemptCtx)
+		if err == nil && attemptCtx.Err() != nil {
+			err = attemptCtx.Err()
+		}
+		cancel()
+
+		run.Rows += rows
+		if err == nil || run.Attempts > job.Retries || !s.sleep(ctx, backoff) {
+			break
+		}
+		slog.Warn("повтор фоновой задачи", slog.String("job", job.Name), slog.Int("attempt", run.Attempts+1), slog.String("err", err.Error()))
+		backoff *= 2
+	}
+
+	run.FinishedAt = s.now()
+	run.Status = domain.JobRunSuccess
+	if err != nil {
+		run.Status = domain.JobRunFailed
+		run.Error = err.Error()
+	}
+
+	metrics.ObserveJob(job.Name, run.StartedAt, run.Rows, err)
+	return run
+}
+
+// sleep ждёт d; false — контекст отменён раньше
+func (s *Scheduler) sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *Scheduler) setNextRun(job string, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun[job] = next
+}
+
+// Jobs возвращает задачи с ближайшим и последним запуском. Последний запуск берётся из Store,
+// чтобы была видна работа любой реплики; при ошибке Store — из локальной копии
+func (s *Scheduler) Jobs(ctx context.Context) []domain.JobInfo {
+	const op = "internal.scheduler.Jobs"
+
+	infos := make([]domain.JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		last, err := s.store.LastRun(ctx, job.Name)
+
+		s.mu.Lock()
+		next := s.nextRun[job.Name]
+		if err != nil {
+			slog.Error(op, "не удалось получить последний запуск", slog.String("job", job.Name), slog.String("err", err.Error()))
+			last = s.lastRun[job.Name]
+		}
+		s.mu.Unlock()
+
+		if next.IsZero() {
+			next = job.Schedule.Next(s.now())
+		}
+
+		infos = append(infos, domain.JobInfo{
+			Name:     job.Name,
+			Schedule: job.Schedule.String(),
+			Timeout:  job.Timeout.String(),
+			Retries:  job.Retries,
+			NextRun:  next,
+			LastRun:  last,
+		})
+	}
+
+	return infos
+}
@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore хранилище в памяти; общее для нескольких планировщиков, как Redis для реплик
+type memoryStore struct {
+	mu     sync.Mutex
+	leases map[string]bool
+	runs   map[string]*domain.JobRun
+	err    error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{leases: map[string]bool{}, runs: map[string]*domain.JobRun{}}
+}
+
+func (s *memoryStore) AcquireLease(ctx context.Context, job string, tick time.Time, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	key := job + tick.String()
+	if s.leases[key] {
+		return false, nil
+	}
+	s.leases[key] = true
+	return true, nil
+}
+
+func (s *memoryStore) SaveRun(ctx context.Context, job string, run *domain.JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[job] = run
+	return nil
+}
+
+func (s *memoryStore) LastRun(ctx context.Context, job string) (*domain.JobRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.runs[job], s.err
+}
+
+func testJob(t *testing.T, run RunFunc) Job {
+	schedule, err := ParseSchedule("@hourly")
+	require.NoError(t, err)
+	return Job{Name: "test", Schedule: schedule, Timeout: time.Second, Retries: 2, Backoff: time.Millisecond, Run: run}
+}
+
+func TestScheduler_RunTick(t *testing.T) {
+	tick := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("запуск выполняет только одна реплика", func(t *testing.T) {
+		store := newMemoryStore()
+		var calls int
+		job := testJob(t, func(ctx context.Context) (int64, error) {
+			calls++
+			return 5, nil
+		})
+
+		first, err := New(store, "a", job)
+		require.NoError(t, err)
+		second, err := New(store, "b", job)
+		require.NoError(t, err)
+
+		first.runTick(context.Background(), job, tick)
+		second.runTick(context.Background(), job, tick)
+
+		assert.Equal(t, 1, calls)
+		require.NotNil(t, store.runs["test"])
+		assert.Equal(t, domain.JobRunSuccess, store.runs["test"].Status)
+		assert.Equal(t, int64(5), store.runs["test"].Rows)
+		assert.Equal(t, "a", store.runs["test"].Instance)
+
+		infos := second.Jobs(context.Background())
+		require.Len(t, infos, 1)
+		assert.Equal(t, "a", infos[0].LastRun.Instance, "результат запуска виден на любой реплике")
+	})
+
+	t.Run("ошибки повторяются до успеха", func(t *testing.T) {
+		store := newMemoryStore()
+		var calls int
+		job := testJob(t, func(ctx context.Context) (int64, error) {
+			calls++
+			if calls < 3 {
+				return 0, errors.New("временная ошибка")
+			}
+			return 1, nil
+		})
+		s, err := New(store, "a", job)
+		require.NoError(t, err)
+
+		s.runTick(context.Background(), job, tick)
+
+		assert.Equal(t, 3, store.runs["test"].Attempts)
+		assert.Equal(t, domain.JobRunSuccess, store.runs["test"].Status)
+	})
+
+	t.Run("таймаут каждой попытки и исчерпание повторов", func(t *testing.T) {
+		store := newMemoryStore()
+		job := testJob(t, func(ctx context.Context) (int64, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		job.Timeout = 10 * time.Millisecond
+		s, err := New(store, "a", job)
+		require.NoError(t, err)
+
+		s.runTick(context.Background(), job, tick)
+
+		run := store.runs["test"]
+		assert.Equal(t, domain.JobRunFailed, run.Status)
+		assert.Equal(t, 3, run.Attempts)
+		assert.Contains(t, run.Error, context.DeadlineExceeded.Error())
+	})
+
+	t.Run("без аренды запуск пропускается", func(t *testing.T) {
+		store := newMemoryStore()
+		store.err = errors.New("redis недоступен")
+		var calls int
+		job := testJob(t, func(ctx context.Context) (int64, error) {
+			calls++
+			return 0, nil
+		})
+		s, err := New(store, "a", job)
+		require.NoError(t, err)
+
+		s.runTick(context.Background(), job, tick)
+		assert.Zero(t, calls)
+	})
+}
+
+func TestScheduler_Run(t *testing.T) {
+	store := newMemoryStore()
+	job := testJob(t, func(ctx context.Context) (int64, error) { return 0, nil })
+	s, err := New(store, "a", job)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 3, 1, 10, 17, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		infos := s.Jobs(context.Background())
+		return infos[0].NextRun.Equal(time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC))
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("планировщик не остановился после отмены контекста")
+	}
+}
+
+func TestNew_InvalidJobs(t *testing.T) {
+	job := testJob(t, func(ctx context.Context) (int64, error) { return 0, nil })
+
+	_, err := New(newMemoryStore(), "a", job, job)
+	assert.Error(t, err, "имена задач должны быть уникальны")
+
+	job.Timeout = 0
+	_, err = New(newMemoryStore(), "a", job)
+	assert.Error(t, err)
+}
+
+func TestNew_RunLongerThanInterval(t *testing.T) {
+	job := testJob(t, func(ctx context.Context) (int64, error) { return 0, nil })
+	schedule, err := ParseSchedule("*/15 * * * *")
+	require.NoError(t, err)
+	job.Schedule = schedule
+
+	// Настройки по умолчанию: 4 попытки по 5 минут и паузы 10s, 20s, 40s — дольше 15 минут
+	job.Timeout, job.Retries, job.Backoff = 5*time.Minute, 3, 10*time.Second
+	assert.Equal(t, 21*time.Minute+10*time.Second, job.maxDuration())
+	_, err = New(newMemoryStore(), "a", job)
+	assert.ErrorContains(t, err, "15m0s")
+
+	job.Retries = 1
+	_, err = New(newMemoryStore(), "a", job)
+	assert.NoError(t, err, "две попытки по 5 минут укладываются в интервал")
+}
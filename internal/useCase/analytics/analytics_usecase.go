@@ -2,22 +2,38 @@ package analytics
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/metrics"
 	"context"
 	"fmt"
 	"log/slog"
-	"regexp"
-	"strconv"
+	"time"
+)
+
+// Защита от одновременного пересчёта: при промахе кэша агрегаты считает только запрос, захвативший блокировку,
+// остальные ждут, пока он заполнит кэш. Переменные, а не константы, чтобы тесты могли сократить ожидание
+var (
+	lockTTL          = 30 * time.Second       // Не даёт блокировке зависнуть, если держатель упал
+	lockWait         = 5 * time.Second        // Сколько ждать чужой пересчёт, прежде чем посчитать самим
+	lockPollInterval = 100 * time.Millisecond // Как часто проверять кэш во время ожидания
 )
 
 type TaskAnalyticsRepository interface {
-	GetTaskCountByStatus(ctx context.Context) (map[string]int, error)
-	GetAverageExecutionTime(ctx context.Context) (string, error)
-	GetReportPeriod(ctx context.Context) (*domain.ReportPeriod, error)
+	GetTaskCountByStatus(ctx context.Context, ownerID int64) (map[string]int, error)
+	GetExecutionTimeStats(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ExecutionTimeStats, error)
+	GetReportPeriod(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ReportPeriod, error)
+	GetTimeSeries(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) ([]domain.TimeSeriesPoint, error)
+	GetBurndown(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod, priorities []domain.Priority) ([]domain.BurndownPoint, error)
 }
 
 type RedisRepoAnalytics interface {
-	GetAnalytics(ctx context.Context) (*domain.AnalyticsTasksResponse, error)
-	SetAnalytics(ctx context.Context, analytics *domain.AnalyticsTasksResponse) error
+	CacheVersion(ctx context.Context, ownerID int64) (string, error)
+	InvalidateAnalytics(ctx context.Context, ownerID int64) error
+	TryLock(ctx context.Context, name string, ttl time.Duration) (string, error)
+	Unlock(ctx context.Context, name, token string) error
+	GetAnalytics(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error)
+	SetAnalytics(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, analytics *domain.AnalyticsTasksResponse) error
+	GetTimeSeries(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) (*domain.TimeSeries, error)
+	SetTimeSeries(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, series *domain.TimeSeries) error
 }
 
 type TaskAnalyticsUseCase struct {
@@ -32,74 +48,200 @@ func NewAnalyticsUseCase(taskRepository TaskAnalyticsRepository, redisRepo Redis
 	}
 }
 
-func (uc *TaskAnalyticsUseCase) GetAnalytics(ctx context.Context) (*domain.AnalyticsTasksResponse, error) {
+// GetAnalytics возвращает аналитику за период: количество задач по статусам на текущий момент,
+// время выполнения задач, выполненных в периоде, и отчёт о выполненных и просроченных задачах
+func (uc *TaskAnalyticsUseCase) GetAnalytics(ctx context.Context, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error) {
 	const op = "internal.useCase.analytics_useCase.GetAnalytics"
 
-	// Пробуем получить данные из кэша
-	cachedAnalytics, err := uc.redisRepo.GetAnalytics(ctx)
-	if err == nil && cachedAnalytics != nil {
-		return cachedAnalytics, nil
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
 	}
 
+	return loadCached(ctx, uc, op, user.ID, "summary", period.CacheKey(),
+		func(version string) (*domain.AnalyticsTasksResponse, error) {
+			return uc.redisRepo.GetAnalytics(ctx, user.ID, version, period)
+		},
+		func(version string, analytics *domain.AnalyticsTasksResponse) error {
+			return uc.redisRepo.SetAnalytics(ctx, user.ID, version, period, analytics)
+		},
+		func() (*domain.AnalyticsTasksResponse, error) {
+			return uc.computeAnalytics(ctx, user.ID, period)
+		},
+	)
+}
+
+// computeAnalytics считает аналитику тремя агрегирующими запросами
+func (uc *TaskAnalyticsUseCase) computeAnalytics(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error) {
 	// 1. Получаем количество задач по статусам
-	statusCounts, err := uc.taskRepository.GetTaskCountByStatus(ctx)
+	statusCounts, err := uc.taskRepository.GetTaskCountByStatus(ctx, ownerID)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось получить количество задач по статусам: %w", err)
 	}
 
-	// 2. Получаем среднее время выполнения задач
-	avgExecutionTime, err := uc.taskRepository.GetAverageExecutionTime(ctx)
+	// 2. Получаем время выполнения задач
+	executionTime, err := uc.taskRepository.GetExecutionTimeStats(ctx, ownerID, period)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось получить среднее время выполнения задач: %w", err)
-	}
-
-	finalAvgExecutionTime, err := formatExecutionTime(avgExecutionTime)
-	if err != nil {
-		slog.Error(op, "ошибка форматирования времени", slog.String("error", err.Error()))
-		return nil, err
+		return nil, fmt.Errorf("не удалось получить время выполнения задач: %w", err)
 	}
 
 	// 3. Получаем отчет по задачам за период
-	report, err := uc.taskRepository.GetReportPeriod(ctx)
+	report, err := uc.taskRepository.GetReportPeriod(ctx, ownerID, period)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось получить отчет по задачам: %w", err)
 	}
 
-	analyticsResponse := &domain.AnalyticsTasksResponse{
-		StatusCounts:         statusCounts,
-		AverageExecutionTime: finalAvgExecutionTime,
-		ReportLastPeriod:     report,
+	return &domain.AnalyticsTasksResponse{
+		StatusCounts:     statusCounts,
+		LeadTime:         executionTime.LeadTime,
+		CycleTime:        executionTime.CycleTime,
+		ReportLastPeriod: report,
+	}, nil
+}
+
+// GetTimeSeries возвращает количество созданных, выполненных и просроченных задач по дням или неделям периода
+func (uc *TaskAnalyticsUseCase) GetTimeSeries(ctx context.Context, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) (*domain.TimeSeries, error) {
+	const op = "internal.useCase.analytics_useCase.GetTimeSeries"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
 	}
 
-	if err = uc.redisRepo.SetAnalytics(ctx, analyticsResponse); err != nil {
-		slog.Error(op, "ошибка сохранения данных в кэш", slog.String("err", err.Error()))
+	return loadCached(ctx, uc, op, user.ID, "timeseries", string(bucket)+":"+period.CacheKey(),
+		func(version string) (*domain.TimeSeries, error) {
+			return uc.redisRepo.GetTimeSeries(ctx, user.ID, version, period, bucket)
+		},
+		func(version string, series *domain.TimeSeries) error {
+			return uc.redisRepo.SetTimeSeries(ctx, user.ID, version, period, series)
+		},
+		func() (*domain.TimeSeries, error) {
+			points, err := uc.taskRepository.GetTimeSeries(ctx, user.ID, period, bucket)
+			if err != nil {
+				return nil, fmt.Errorf("не удалось получить временной ряд задач: %w", err)
+			}
+			return &domain.TimeSeries{
+				From:   period.From,
+				To:     period.To,
+				Bucket: bucket,
+				Points: points,
+			}, nil
+		},
+	)
+}
+
+// GetBurndown возвращает остаток и выполненные задачи по дням периода с идеальной линией сгорания.
+// Данные берутся из ежедневных снимков, поэтому не кэшируются: запрос по снимкам дешёвый
+func (uc *TaskAnalyticsUseCase) GetBurndown(ctx context.Context, period *domain.AnalyticsPeriod, priorities []domain.Priority) (*domain.Burndown, error) {
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
 	}
 
-	return analyticsResponse, nil
+	points, err := uc.taskRepository.GetBurndown(ctx, user.ID, period, priorities)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить данные burndown: %w", err)
+	}
+
+	burndown := &domain.Burndown{
+		From:       period.From,
+		To:         period.To,
+		Priorities: priorities,
+		Points:     points,
+	}
+	burndown.ProjectIdeal()
+
+	return burndown, nil
 }
 
-func formatExecutionTime(timeStr string) (string, error) {
-	re := regexp.MustCompile(`(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?`)
-	matches := re.FindStringSubmatch(timeStr)
+// HandleTaskChange сбрасывает кэш аналитики владельца изменённых задач (или всех пользователей при OwnerID == 0).
+// Подписывается на шину изменений задач
+func (uc *TaskAnalyticsUseCase) HandleTaskChange(ctx context.Context, change domain.TaskChange) {
+	const op = "internal.useCase.analytics_useCase.HandleTaskChange"
+
+	if err := uc.redisRepo.InvalidateAnalytics(ctx, change.OwnerID); err != nil {
+		slog.Error(op, "не удалось сбросить кэш аналитики",
+			slog.Int64("owner_id", change.OwnerID),
+			slog.String("event", string(change.Type)),
+			slog.String("err", err.Error()),
+		)
+	}
+}
 
-	if len(matches) == 0 {
-		return "", fmt.Errorf("не удалось распарсить строку времени: %s", timeStr)
+// loadCached возвращает значение из кэша, а при промахе вычисляет его через compute и сохраняет.
+// Версия данных читается до вычисления: если задачи изменятся во время пересчёта,
+// результат ляжет под старую версию и не будет прочитан.
+// Пересчитывает значение только запрос, захвативший блокировку на kind и key; остальные ждут до lockWait,
+// а если кэш так и не заполнился (держатель упал или Redis недоступен), считают сами.
+// kind — вид аналитики для метрик попаданий в кэш, key — параметры запроса
+func loadCached[T any](
+	ctx context.Context,
+	uc *TaskAnalyticsUseCase,
+	op string,
+	ownerID int64,
+	kind, key string,
+	get func(version string) (*T, error),
+	set func(version string, value *T) error,
+	compute func() (*T, error),
+) (*T, error) {
+	version, err := uc.redisRepo.CacheVersion(ctx, ownerID)
+	if err != nil {
+		// Без версии нельзя отличить актуальный кэш от устаревшего, поэтому считаем напрямую
+		metrics.CacheMiss(kind)
+		return compute()
 	}
 
-	hours := 0
-	if matches[1] != "" {
-		hours, _ = strconv.Atoi(matches[1])
+	if cached, err := get(version); err == nil && cached != nil {
+		metrics.CacheHit(kind)
+		return cached, nil
 	}
 
-	minutes := 0
-	if matches[2] != "" {
-		minutes, _ = strconv.Atoi(matches[2])
+	lockName := fmt.Sprintf("%d:%s:%s:%s", ownerID, version, kind, key)
+	token, err := uc.redisRepo.TryLock(ctx, lockName, lockTTL)
+	if err == nil && token == "" {
+		if cached, err := waitCached(ctx, get, version); err != nil || cached != nil {
+			if cached != nil {
+				metrics.CacheHit(kind)
+			}
+			return cached, err
+		}
+		slog.Warn(op, "не дождались пересчёта аналитики другим запросом", slog.String("lock", lockName))
+	}
+	if token != "" {
+		defer func() {
+			_ = uc.redisRepo.Unlock(context.WithoutCancel(ctx), lockName, token)
+		}()
 	}
 
-	seconds := 0
-	if matches[3] != "" {
-		seconds, _ = strconv.Atoi(matches[3])
+	metrics.CacheMiss(kind)
+	value, err := compute()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = set(version, value); err != nil {
+		slog.Error(op, "ошибка сохранения данных в кэш", slog.String("err", err.Error()))
 	}
 
-	return fmt.Sprintf("%d часов %d минут %d секунд", hours, minutes, seconds), nil
+	return value, nil
+}
+
+// waitCached опрашивает кэш, пока значение не появится или не истечёт lockWait; nil — не дождались
+func waitCached[T any](ctx context.Context, get func(version string) (*T, error), version string) (*T, error) {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	deadline := time.After(lockWait)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, nil
+		case <-ticker.C:
+			if cached, err := get(version); err == nil && cached != nil {
+				return cached, nil
+			}
+		}
+	}
 }
@@ -3,17 +3,21 @@ package analytics
 import (
 	"GoTasker/internal/domain"
 	"context"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"sync"
 	"testing"
+	"time"
 )
 
 type MockTaskAnalyticsUseCase struct {
 	mock.Mock
 }
 
-func (m *MockTaskAnalyticsUseCase) GetAnalytics(ctx context.Context) (*domain.AnalyticsTasksResponse, error) {
-	args := m.Called(ctx)
+func (m *MockTaskAnalyticsUseCase) GetAnalytics(ctx context.Context, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error) {
+	args := m.Called(ctx, period)
 	val := args.Get(0)
 	if val == nil {
 		return nil, args.Error(1)
@@ -31,16 +35,17 @@ func TestTaskAnalyticsUseCase_GetAnalytics(t *testing.T) {
 				string(domain.StatusInProgress): 3,
 				string(domain.StatusDone):       2,
 			},
-			AverageExecutionTime: "2h 30m",
+			LeadTime:  domain.DurationStats{Count: 2, AverageSeconds: 9000, MedianSeconds: 9000, P90Seconds: 9000, P95Seconds: 9000},
+			CycleTime: domain.DurationStats{Count: 2, AverageSeconds: 5400, MedianSeconds: 5400, P90Seconds: 5400, P95Seconds: 5400},
 			ReportLastPeriod: &domain.ReportPeriod{
 				CompletedTasks: 5,
 				OverdueTasks:   4,
 			},
 		}
 
-		mockUseCase.On("GetAnalytics", mock.Anything).Return(expectedResponse, nil)
+		mockUseCase.On("GetAnalytics", mock.Anything, mock.Anything).Return(expectedResponse, nil)
 
-		response, err := mockUseCase.GetAnalytics(context.Background())
+		response, err := mockUseCase.GetAnalytics(context.Background(), nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedResponse, response)
@@ -50,9 +55,9 @@ func TestTaskAnalyticsUseCase_GetAnalytics(t *testing.T) {
 	t.Run("ошибка при получении аналитики", func(t *testing.T) {
 		mockUseCase.ExpectedCalls = nil
 
-		mockUseCase.On("GetAnalytics", mock.Anything).Return(nil, assert.AnError)
+		mockUseCase.On("GetAnalytics", mock.Anything, mock.Anything).Return(nil, assert.AnError)
 
-		response, err := mockUseCase.GetAnalytics(context.Background())
+		response, err := mockUseCase.GetAnalytics(context.Background(), nil)
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
@@ -63,70 +68,344 @@ func TestTaskAnalyticsUseCase_GetAnalytics(t *testing.T) {
 		mockUseCase.ExpectedCalls = nil
 
 		expectedResponse := &domain.AnalyticsTasksResponse{
-			StatusCounts:         make(map[string]int),
-			AverageExecutionTime: "",
-			ReportLastPeriod:     nil,
+			StatusCounts:     make(map[string]int),
+			ReportLastPeriod: nil,
 		}
 
-		mockUseCase.On("GetAnalytics", mock.Anything).Return(expectedResponse, nil)
+		mockUseCase.On("GetAnalytics", mock.Anything, mock.Anything).Return(expectedResponse, nil)
 
-		response, err := mockUseCase.GetAnalytics(context.Background())
+		response, err := mockUseCase.GetAnalytics(context.Background(), nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedResponse, response)
 		mockUseCase.AssertExpectations(t)
 	})
 
-	t.Run("некорректный формат времени", func(t *testing.T) {
+	t.Run("пустой отчет за период", func(t *testing.T) {
 		mockUseCase.ExpectedCalls = nil
 
 		expectedResponse := &domain.AnalyticsTasksResponse{
 			StatusCounts: map[string]int{
-				string(domain.StatusPending):    5,
-				string(domain.StatusInProgress): 3,
-				string(domain.StatusDone):       2,
+				string(domain.StatusPending):    0,
+				string(domain.StatusInProgress): 0,
+				string(domain.StatusDone):       0,
 			},
-			AverageExecutionTime: "invalid_time_format", // некорректный формат
 			ReportLastPeriod: &domain.ReportPeriod{
-				CompletedTasks: 5,
-				OverdueTasks:   4,
+				CompletedTasks: 0,
+				OverdueTasks:   0,
 			},
 		}
 
-		mockUseCase.On("GetAnalytics", mock.Anything).Return(expectedResponse, nil)
+		mockUseCase.On("GetAnalytics", mock.Anything, mock.Anything).Return(expectedResponse, nil)
 
-		response, err := mockUseCase.GetAnalytics(context.Background())
+		response, err := mockUseCase.GetAnalytics(context.Background(), nil)
 
 		assert.NoError(t, err)
 		assert.Equal(t, expectedResponse, response)
+		mockUseCase.AssertExpectations(t)
+	})
+}
 
-		assert.Equal(t, "invalid_time_format", response.AverageExecutionTime)
+type stubAnalyticsRepo struct {
+	stats    *domain.ExecutionTimeStats
+	points   []domain.TimeSeriesPoint
+	burndown []domain.BurndownPoint
+	calls    int
+}
 
-		mockUseCase.AssertExpectations(t)
+func (r *stubAnalyticsRepo) GetTaskCountByStatus(ctx context.Context, ownerID int64) (map[string]int, error) {
+	return map[string]int{string(domain.StatusDone): r.stats.LeadTime.Count}, nil
+}
+
+func (r *stubAnalyticsRepo) GetExecutionTimeStats(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ExecutionTimeStats, error) {
+	return r.stats, nil
+}
+
+func (r *stubAnalyticsRepo) GetReportPeriod(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod) (*domain.ReportPeriod, error) {
+	return &domain.ReportPeriod{From: period.From, To: period.To}, nil
+}
+
+func (r *stubAnalyticsRepo) GetTimeSeries(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) ([]domain.TimeSeriesPoint, error) {
+	r.calls++
+	return r.points, nil
+}
+
+func (r *stubAnalyticsRepo) GetBurndown(ctx context.Context, ownerID int64, period *domain.AnalyticsPeriod, priorities []domain.Priority) ([]domain.BurndownPoint, error) {
+	return r.burndown, nil
+}
+
+// stubAnalyticsCache кэш в памяти с ключом из версии данных и параметров запроса, как в Redis
+type stubAnalyticsCache struct {
+	mu       sync.Mutex
+	saved    map[string]interface{}
+	versions map[int64]int
+	locks    map[string]string
+}
+
+func cacheKey(ownerID int64, version string, parts ...string) string {
+	return fmt.Sprint(ownerID, version, parts)
+}
+
+func (c *stubAnalyticsCache) CacheVersion(ctx context.Context, ownerID int64) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("%d.%d", c.versions[0], c.versions[ownerID]), nil
+}
+
+func (c *stubAnalyticsCache) InvalidateAnalytics(ctx context.Context, ownerID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[ownerID]++
+	return nil
+}
+
+func (c *stubAnalyticsCache) TryLock(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, held := c.locks[name]; held {
+		return "", nil
+	}
+	c.locks[name] = "token"
+	return "token", nil
+}
+
+func (c *stubAnalyticsCache) Unlock(ctx context.Context, name, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locks[name] == token {
+		delete(c.locks, name)
+	}
+	return nil
+}
+
+func (c *stubAnalyticsCache) get(key string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.saved[key]
+}
+
+func (c *stubAnalyticsCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved[key] = value
+}
+
+func (c *stubAnalyticsCache) GetAnalytics(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod) (*domain.AnalyticsTasksResponse, error) {
+	analytics, _ := c.get(cacheKey(ownerID, version, period.CacheKey())).(*domain.AnalyticsTasksResponse)
+	return analytics, nil
+}
+
+func (c *stubAnalyticsCache) SetAnalytics(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, analytics *domain.AnalyticsTasksResponse) error {
+	c.set(cacheKey(ownerID, version, period.CacheKey()), analytics)
+	return nil
+}
+
+func (c *stubAnalyticsCache) GetTimeSeries(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, bucket domain.TimeBucket) (*domain.TimeSeries, error) {
+	series, _ := c.get(cacheKey(ownerID, version, string(bucket), period.CacheKey())).(*domain.TimeSeries)
+	return series, nil
+}
+
+func (c *stubAnalyticsCache) SetTimeSeries(ctx context.Context, ownerID int64, version string, period *domain.AnalyticsPeriod, series *domain.TimeSeries) error {
+	c.set(cacheKey(ownerID, version, string(series.Bucket), period.CacheKey()), series)
+	return nil
+}
+
+func newStubCache() *stubAnalyticsCache {
+	return &stubAnalyticsCache{
+		saved:    map[string]interface{}{},
+		versions: map[int64]int{},
+		locks:    map[string]string{},
+	}
+}
+
+func TestTaskAnalyticsUseCase_ExecutionTime(t *testing.T) {
+	ctx := domain.ContextWithUser(context.Background(), &domain.AuthUser{ID: 1})
+	stats := &domain.ExecutionTimeStats{
+		LeadTime:  domain.DurationStats{Count: 3, AverageSeconds: 7200, MedianSeconds: 3600, P90Seconds: 14400, P95Seconds: 15000},
+		CycleTime: domain.DurationStats{Count: 2, AverageSeconds: 1800, MedianSeconds: 1800, P90Seconds: 3000, P95Seconds: 3150},
+	}
+	cache := newStubCache()
+	uc := NewAnalyticsUseCase(&stubAnalyticsRepo{stats: stats}, cache)
+
+	period, err := domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{Period: "30d"}, time.Now())
+	require.NoError(t, err)
+
+	response, err := uc.GetAnalytics(ctx, period)
+	assert.NoError(t, err)
+	assert.Equal(t, stats.LeadTime, response.LeadTime)
+	assert.Equal(t, stats.CycleTime, response.CycleTime)
+	assert.Equal(t, period.From, response.ReportLastPeriod.From)
+
+	cached, err := cache.GetAnalytics(ctx, 1, "0.0", period)
+	assert.NoError(t, err)
+	assert.Same(t, response, cached)
+
+	_, err = uc.GetAnalytics(context.Background(), period)
+	assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+}
+
+func TestTaskAnalyticsUseCase_GetTimeSeries(t *testing.T) {
+	ctx := domain.ContextWithUser(context.Background(), &domain.AuthUser{ID: 1})
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &stubAnalyticsRepo{points: []domain.TimeSeriesPoint{
+		{Start: now.AddDate(0, 0, -1).Truncate(24 * time.Hour), Created: 2, Completed: 1},
+		{Start: now.Truncate(24 * time.Hour), Overdue: 1},
+	}}
+	uc := NewAnalyticsUseCase(repo, newStubCache())
+
+	week, err := domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{Period: "7d"}, now)
+	require.NoError(t, err)
+
+	series, err := uc.GetTimeSeries(ctx, week, domain.BucketDay)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BucketDay, series.Bucket)
+	assert.Equal(t, week.From, series.From)
+	assert.Equal(t, repo.points, series.Points)
+
+	t.Run("повторный запрос с теми же параметрами берётся из кэша", func(t *testing.T) {
+		_, err = uc.GetTimeSeries(ctx, week, domain.BucketDay)
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo.calls)
 	})
 
-	t.Run("пустой отчет за период", func(t *testing.T) {
-		mockUseCase.ExpectedCalls = nil
+	t.Run("другие параметры не попадают в кэш первого запроса", func(t *testing.T) {
+		_, err = uc.GetTimeSeries(ctx, week, domain.BucketWeek)
+		require.NoError(t, err)
 
-		expectedResponse := &domain.AnalyticsTasksResponse{
-			StatusCounts: map[string]int{
-				string(domain.StatusPending):    0,
-				string(domain.StatusInProgress): 0,
-				string(domain.StatusDone):       0,
-			},
-			AverageExecutionTime: "0h 0m 0s",
-			ReportLastPeriod: &domain.ReportPeriod{
-				CompletedTasks: 0,
-				OverdueTasks:   0,
-			},
-		}
+		month, err := domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{Period: "30d"}, now)
+		require.NoError(t, err)
+		_, err = uc.GetTimeSeries(ctx, month, domain.BucketDay)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, repo.calls)
+	})
+}
 
-		mockUseCase.On("GetAnalytics", mock.Anything).Return(expectedResponse, nil)
+func TestTaskAnalyticsUseCase_Invalidation(t *testing.T) {
+	ctx := domain.ContextWithUser(context.Background(), &domain.AuthUser{ID: 1})
+	repo := &stubAnalyticsRepo{}
+	cache := newStubCache()
+	uc := NewAnalyticsUseCase(repo, cache)
 
-		response, err := mockUseCase.GetAnalytics(context.Background())
+	week, err := domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{Period: "7d"}, time.Now())
+	require.NoError(t, err)
 
-		assert.NoError(t, err)
-		assert.Equal(t, expectedResponse, response)
-		mockUseCase.AssertExpectations(t)
+	load := func() {
+		_, err := uc.GetTimeSeries(ctx, week, domain.BucketDay)
+		require.NoError(t, err)
+	}
+
+	load()
+	load()
+	assert.Equal(t, 1, repo.calls)
+
+	t.Run("изменение задач другого пользователя не сбрасывает кэш", func(t *testing.T) {
+		uc.HandleTaskChange(ctx, domain.TaskChange{OwnerID: 2, Type: domain.TaskEventCreated})
+		load()
+		assert.Equal(t, 1, repo.calls)
 	})
+
+	t.Run("изменение своих задач сбрасывает кэш", func(t *testing.T) {
+		uc.HandleTaskChange(ctx, domain.TaskChange{OwnerID: 1, Type: domain.TaskEventUpdated})
+		load()
+		load()
+		assert.Equal(t, 2, repo.calls)
+	})
+
+	t.Run("фоновая очистка сбрасывает кэш всех пользователей", func(t *testing.T) {
+		uc.HandleTaskChange(ctx, domain.TaskChange{OwnerID: 0, Type: domain.TaskEventTrashed})
+		load()
+		assert.Equal(t, 3, repo.calls)
+	})
+}
+
+func TestTaskAnalyticsUseCase_StampedeGuard(t *testing.T) {
+	ctx := domain.ContextWithUser(context.Background(), &domain.AuthUser{ID: 1})
+	week, err := domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{Period: "7d"}, time.Now())
+	require.NoError(t, err)
+
+	defaultWait, defaultPoll := lockWait, lockPollInterval
+	lockWait, lockPollInterval = 200*time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { lockWait, lockPollInterval = defaultWait, defaultPoll })
+
+	lockName := fmt.Sprintf("%d:%s:%s:%s", 1, "0.0", "timeseries", "day:"+week.CacheKey())
+
+	t.Run("пока пересчёт идёт в другом запросе, ждём его результат", func(t *testing.T) {
+		repo := &stubAnalyticsRepo{}
+		cache := newStubCache()
+		uc := NewAnalyticsUseCase(repo, cache)
+
+		token, err := cache.TryLock(ctx, lockName, lockTTL)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		expected := &domain.TimeSeries{Bucket: domain.BucketDay}
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			_ = cache.SetTimeSeries(ctx, 1, "0.0", week, expected)
+		}()
+
+		series, err := uc.GetTimeSeries(ctx, week, domain.BucketDay)
+		require.NoError(t, err)
+		assert.Same(t, expected, series)
+		assert.Zero(t, repo.calls)
+	})
+
+	t.Run("не дождавшись, считаем сами", func(t *testing.T) {
+		repo := &stubAnalyticsRepo{}
+		cache := newStubCache()
+		uc := NewAnalyticsUseCase(repo, cache)
+
+		_, err := cache.TryLock(ctx, lockName, lockTTL)
+		require.NoError(t, err)
+
+		_, err = uc.GetTimeSeries(ctx, week, domain.BucketDay)
+		require.NoError(t, err)
+		assert.Equal(t, 1, repo.calls)
+	})
+
+	t.Run("блокировка снимается после пересчёта", func(t *testing.T) {
+		cache := newStubCache()
+		uc := NewAnalyticsUseCase(&stubAnalyticsRepo{}, cache)
+
+		_, err := uc.GetTimeSeries(ctx, week, domain.BucketDay)
+		require.NoError(t, err)
+		assert.Empty(t, cache.locks)
+	})
+}
+
+func TestTaskAnalyticsUseCase_GetBurndown(t *testing.T) {
+	ctx := domain.ContextWithUser(context.Background(), &domain.AuthUser{ID: 1})
+	count := func(n int) *int { return &n }
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	period, err := domain.ParseAnalyticsPeriod(domain.AnalyticsPeriodParams{From: "2025-03-01", To: "2025-03-05"}, time.Now())
+	require.NoError(t, err)
+
+	repo := &stubAnalyticsRepo{burndown: []domain.BurndownPoint{
+		{Date: day(1)}, // Снимка ещё нет
+		{Date: day(2), Remaining: count(6), Completed: count(0)},
+		{Date: day(3), Remaining: count(5), Completed: count(2)},
+		{Date: day(4)},
+		{Date: day(5), Remaining: count(1), Completed: count(6)},
+	}}
+	uc := NewAnalyticsUseCase(repo, newStubCache())
+
+	burndown, err := uc.GetBurndown(ctx, period, []domain.Priority{domain.PriorityHigh})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Priority{domain.PriorityHigh}, burndown.Priorities)
+	assert.Equal(t, period.From, burndown.From)
+
+	ideal := make([]*float64, len(burndown.Points))
+	for i, p := range burndown.Points {
+		ideal[i] = p.Ideal
+	}
+	assert.Nil(t, ideal[0], "до первого снимка идеальная линия не строится")
+	for i, want := range []float64{6, 4, 2, 0} {
+		require.NotNil(t, ideal[i+1])
+		assert.InDelta(t, want, *ideal[i+1], 1e-9)
+	}
+
+	_, err = uc.GetBurndown(context.Background(), period, nil)
+	assert.ErrorIs(t, err, domain.ErrUnauthenticated)
 }
@@ -3,10 +3,13 @@ package auth
 import (
 	"GoTasker/internal/config"
 	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
 	"GoTasker/pkg/utils"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 type UserPostgresRepo interface {
@@ -14,15 +17,26 @@ type UserPostgresRepo interface {
 	FindByEmail(ctx context.Context, email string) (*domain.User, error)
 }
 
+type TokenRedisRepo interface {
+	SaveRefreshFamily(ctx context.Context, familyID, tokenID string, ttl time.Duration) error
+	RotateRefreshToken(ctx context.Context, familyID, oldID, newID string, ttl time.Duration) error
+	RevokeRefreshFamily(ctx context.Context, familyID string) error
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID int64, at time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error)
+}
+
 type UserAuthUseCase struct {
-	userRepo UserPostgresRepo
-	cfg      *config.Config
+	userRepo  UserPostgresRepo
+	tokenRepo TokenRedisRepo
+	cfg       *config.Config
 }
 
-func NewAuthUseCase(userRepo UserPostgresRepo, cfg *config.Config) *UserAuthUseCase {
+func NewAuthUseCase(userRepo UserPostgresRepo, tokenRepo TokenRedisRepo, cfg *config.Config) *UserAuthUseCase {
 	return &UserAuthUseCase{
-		userRepo: userRepo,
-		cfg:      cfg,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		cfg:       cfg,
 	}
 }
 
@@ -58,6 +72,9 @@ func (uc *UserAuthUseCase) Login(ctx context.Context, email, password string) (s
 
 	user, err := uc.userRepo.FindByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return "", "", domain.ErrInvalidCredentials
+		}
 		return "", "", err
 	}
 
@@ -68,41 +85,165 @@ func (uc *UserAuthUseCase) Login(ctx context.Context, email, password string) (s
 			slog.String("error", err.Error()),
 			slog.String("email", email),
 		)
-		return "", "", fmt.Errorf("неверный пароль")
+		return "", "", domain.ErrInvalidCredentials
+	}
+
+	familyID, err := utils.NewTokenID()
+	if err != nil {
+		slog.Error(op, "ошибка при генерации идентификатора сессии", slog.String("error", err.Error()))
+		return "", "", fmt.Errorf("ошибка при генерации токенов: %w", err)
+	}
+
+	accessToken, refreshToken, refreshID, err := uc.issueTokens(user, familyID)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = uc.tokenRepo.SaveRefreshFamily(ctx, familyID, refreshID, uc.cfg.Server.RefreshDuration)
+	if err != nil {
+		return "", "", err
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.Username,
+	return accessToken, refreshToken, nil
+
+}
+
+// Refresh обменивает refresh токен на новую пару токенов. Использованный
+// refresh токен становится недействительным, а его повторное предъявление
+// отзывает всю сессию.
+func (uc *UserAuthUseCase) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+	const op = "internal.useCase.auth.Refresh"
+
+	claims, err := utils.ParseRefreshToken(refreshToken, []byte(uc.cfg.Server.JWTSecret))
+	if err != nil {
+		slog.Debug(op, "refresh токен отклонён", slog.String("error", err.Error()))
+		return "", "", domain.ErrInvalidRefreshToken
+	}
+	if claims.FamilyID == "" || claims.ID == "" || claims.IssuedAt == nil {
+		return "", "", domain.ErrInvalidRefreshToken
+	}
+
+	revoked, err := uc.tokenRepo.IsTokenRevoked(ctx, claims.ID, claims.UserID, claims.IssuedAtTime())
+	if err != nil {
+		return "", "", err
+	}
+	if revoked {
+		return "", "", domain.ErrSessionRevoked
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, claims.Email)
+	if err != nil || user.ID != claims.UserID {
+		return "", "", domain.ErrInvalidRefreshToken
+	}
+
+	accessToken, newRefreshToken, refreshID, err := uc.issueTokens(user, claims.FamilyID)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = uc.tokenRepo.RotateRefreshToken(ctx, claims.FamilyID, claims.ID, refreshID, uc.cfg.Server.RefreshDuration)
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			slog.Warn(op, "обнаружено повторное использование refresh токена",
+				slog.Int64("user_id", user.ID),
+				slog.String("family_id", claims.FamilyID),
+			)
+		}
+		return "", "", err
+	}
+
+	return accessToken, newRefreshToken, nil
+}
+
+// Logout завершает текущую сессию: access токен попадает в deny-list,
+// а refresh токены сессии отзываются
+func (uc *UserAuthUseCase) Logout(ctx context.Context) error {
+	const op = "internal.useCase.auth.Logout"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if err := uc.tokenRepo.RevokeToken(ctx, user.TokenID, user.TokenExpiresAt); err != nil {
+		return err
+	}
+
+	if user.SessionID != "" {
+		if err := uc.tokenRepo.RevokeRefreshFamily(ctx, user.SessionID); err != nil {
+			return err
+		}
+	}
+
+	slog.Info(op, "сессия завершена", slog.Int64("user_id", user.ID))
+	return nil
+}
+
+// LogoutAll отзывает все выпущенные пользователю токены во всех сессиях,
+// например после смены пароля
+func (uc *UserAuthUseCase) LogoutAll(ctx context.Context) error {
+	const op = "internal.useCase.auth.LogoutAll"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if err := uc.tokenRepo.RevokeUserTokens(ctx, user.ID, time.Now()); err != nil {
+		return err
+	}
+
+	slog.Info(op, "все сессии пользователя завершены", slog.Int64("user_id", user.ID))
+	return nil
+}
+
+// issueTokens выпускает пару токенов для сессии familyID и возвращает
+// идентификатор нового refresh токена
+func (uc *UserAuthUseCase) issueTokens(user *domain.User, familyID string) (string, string, string, error) {
+	const op = "internal.useCase.auth.issueTokens"
+
+	accessToken, err := utils.GenerateAccessToken(user.ID,
+		user.Username,
 		user.Email,
+		familyID,
 		uc.cfg.Server.JWTSecret,
 		uc.cfg.Server.AccessDuration,
 	)
 	if err != nil {
 		slog.Error(op, "ошибка при генерации access токена", slog.String("error", err.Error()))
-		return "", "", fmt.Errorf("ошибка при генерации токенов: %w", err)
+		return "", "", "", fmt.Errorf("ошибка при генерации токенов: %w", err)
+	}
+
+	refreshID, err := utils.NewTokenID()
+	if err != nil {
+		slog.Error(op, "ошибка при генерации идентификатора токена", slog.String("error", err.Error()))
+		return "", "", "", fmt.Errorf("ошибка при генерации токенов: %w", err)
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.Email,
+	refreshToken, err := utils.GenerateRefreshToken(user.ID,
+		user.Email,
+		familyID,
+		refreshID,
 		uc.cfg.Server.JWTSecret,
 		uc.cfg.Server.RefreshDuration,
 	)
 	if err != nil {
 		slog.Error(op, "ошибка при генерации refresh токена", slog.String("error", err.Error()))
-		return "", "", fmt.Errorf("ошибка при генерации токенов: %w", err)
+		return "", "", "", fmt.Errorf("ошибка при генерации токенов: %w", err)
 	}
 
-	return accessToken, refreshToken, nil
-
+	return accessToken, refreshToken, refreshID, nil
 }
 
 func validateUser(user *domain.User) error {
 	if user.Username == "" {
-		return fmt.Errorf("имя не может быть пустым")
+		return domain.NewFieldError("username", i18n.UsernameRequired)
 	}
 	if user.Email == "" {
-		return fmt.Errorf("email не может быть пустым")
+		return domain.NewFieldError("email", i18n.EmailRequired)
 	}
 	if user.Password == "" {
-		return fmt.Errorf("не указан пароль")
+		return domain.NewFieldError("password", i18n.PasswordRequired)
 	}
 
 	return nil
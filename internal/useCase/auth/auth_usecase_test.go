@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"GoTasker/internal/config"
+	"GoTasker/internal/domain"
+	"GoTasker/pkg/utils"
+	"context"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type mockUserRepo struct {
+	mock.Mock
+}
+
+func (m *mockUserRepo) Create(ctx context.Context, user *domain.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepo) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+type mockTokenRepo struct {
+	mock.Mock
+}
+
+func (m *mockTokenRepo) SaveRefreshFamily(ctx context.Context, familyID, tokenID string, ttl time.Duration) error {
+	args := m.Called(ctx, familyID, tokenID, ttl)
+	return args.Error(0)
+}
+
+func (m *mockTokenRepo) RotateRefreshToken(ctx context.Context, familyID, oldID, newID string, ttl time.Duration) error {
+	args := m.Called(ctx, familyID, oldID, newID, ttl)
+	return args.Error(0)
+}
+
+func (m *mockTokenRepo) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (m *mockTokenRepo) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := m.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *mockTokenRepo) RevokeUserTokens(ctx context.Context, userID int64, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *mockTokenRepo) IsTokenRevoked(ctx context.Context, tokenID string, userID int64, issuedAt time.Time) (bool, error) {
+	args := m.Called(ctx, tokenID, userID, issuedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			JWTSecret:       "test-secret",
+			AccessDuration:  time.Minute,
+			RefreshDuration: time.Hour,
+		},
+	}
+}
+
+func testUser(t *testing.T) *domain.User {
+	hash, err := utils.HashPassword("password123")
+	require.NoError(t, err)
+	return domain.NewUser(7, "john", "john@example.com", hash)
+}
+
+func TestUserAuthUseCase_Login(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+
+	t.Run("успешный вход заводит семейство токенов", func(t *testing.T) {
+		userRepo := new(mockUserRepo)
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(userRepo, tokenRepo, cfg)
+
+		userRepo.On("FindByEmail", ctx, "john@example.com").Return(testUser(t), nil)
+		tokenRepo.On("SaveRefreshFamily", ctx, mock.Anything, mock.Anything, cfg.Server.RefreshDuration).Return(nil)
+
+		accessToken, refreshToken, err := uc.Login(ctx, "john@example.com", "password123")
+		require.NoError(t, err)
+
+		access, err := utils.ParseAccessToken(accessToken, []byte(cfg.Server.JWTSecret))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), access.UserID)
+		assert.NotEmpty(t, access.ID)
+
+		refresh, err := utils.ParseRefreshToken(refreshToken, []byte(cfg.Server.JWTSecret))
+		require.NoError(t, err)
+		assert.Equal(t, refresh.FamilyID, access.SessionID)
+		tokenRepo.AssertCalled(t, "SaveRefreshFamily", ctx, refresh.FamilyID, refresh.ID, cfg.Server.RefreshDuration)
+	})
+}
+
+func TestUserAuthUseCase_Refresh(t *testing.T) {
+	ctx := context.Background()
+	cfg := testConfig()
+
+	newRefreshToken := func(t *testing.T, familyID, tokenID string) string {
+		token, err := utils.GenerateRefreshToken(7, "john@example.com", familyID, tokenID, cfg.Server.JWTSecret, time.Hour)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("refresh токен без iat отклоняется", func(t *testing.T) {
+		uc := NewAuthUseCase(new(mockUserRepo), new(mockTokenRepo), cfg)
+
+		claims := utils.RefreshTokenClaim{
+			UserID:           7,
+			Email:            "john@example.com",
+			FamilyID:         "family-1",
+			TokenType:        utils.TokenTypeRefresh,
+			RegisteredClaims: jwt.RegisteredClaims{ID: "token-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Server.JWTSecret))
+		require.NoError(t, err)
+
+		_, _, err = uc.Refresh(ctx, token)
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	})
+
+	t.Run("успешная ротация", func(t *testing.T) {
+		userRepo := new(mockUserRepo)
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(userRepo, tokenRepo, cfg)
+
+		tokenRepo.On("IsTokenRevoked", ctx, "token-1", int64(7), mock.Anything).Return(false, nil)
+		userRepo.On("FindByEmail", ctx, "john@example.com").Return(testUser(t), nil)
+		tokenRepo.On("RotateRefreshToken", ctx, "family-1", "token-1", mock.Anything, cfg.Server.RefreshDuration).Return(nil)
+
+		accessToken, refreshToken, err := uc.Refresh(ctx, newRefreshToken(t, "family-1", "token-1"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, accessToken)
+
+		refresh, err := utils.ParseRefreshToken(refreshToken, []byte(cfg.Server.JWTSecret))
+		require.NoError(t, err)
+		assert.Equal(t, "family-1", refresh.FamilyID)
+		assert.NotEqual(t, "token-1", refresh.ID)
+	})
+
+	t.Run("повторное использование токена", func(t *testing.T) {
+		userRepo := new(mockUserRepo)
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(userRepo, tokenRepo, cfg)
+
+		tokenRepo.On("IsTokenRevoked", ctx, "token-1", int64(7), mock.Anything).Return(false, nil)
+		userRepo.On("FindByEmail", ctx, "john@example.com").Return(testUser(t), nil)
+		tokenRepo.On("RotateRefreshToken", ctx, "family-1", "token-1", mock.Anything, cfg.Server.RefreshDuration).
+			Return(domain.ErrRefreshTokenReused)
+
+		_, _, err := uc.Refresh(ctx, newRefreshToken(t, "family-1", "token-1"))
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+	})
+
+	t.Run("access токен вместо refresh", func(t *testing.T) {
+		uc := NewAuthUseCase(new(mockUserRepo), new(mockTokenRepo), cfg)
+
+		accessToken, err := utils.GenerateAccessToken(7, "john", "john@example.com", "family-1", cfg.Server.JWTSecret, time.Minute)
+		require.NoError(t, err)
+
+		_, _, err = uc.Refresh(ctx, accessToken)
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	})
+
+	t.Run("токен другого пользователя", func(t *testing.T) {
+		userRepo := new(mockUserRepo)
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(userRepo, tokenRepo, cfg)
+
+		tokenRepo.On("IsTokenRevoked", ctx, "token-1", int64(7), mock.Anything).Return(false, nil)
+		userRepo.On("FindByEmail", ctx, "john@example.com").
+			Return(domain.NewUser(8, "john", "john@example.com", ""), nil)
+
+		_, _, err := uc.Refresh(ctx, newRefreshToken(t, "family-1", "token-1"))
+		assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	})
+
+	t.Run("токены пользователя отозваны", func(t *testing.T) {
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(new(mockUserRepo), tokenRepo, cfg)
+
+		tokenRepo.On("IsTokenRevoked", ctx, "token-1", int64(7), mock.Anything).Return(true, nil)
+
+		_, _, err := uc.Refresh(ctx, newRefreshToken(t, "family-1", "token-1"))
+		assert.ErrorIs(t, err, domain.ErrSessionRevoked)
+	})
+}
+
+func TestUserAuthUseCase_Logout(t *testing.T) {
+	cfg := testConfig()
+	expiresAt := time.Now().Add(time.Minute)
+	ctx := domain.ContextWithUser(context.Background(), &domain.AuthUser{
+		ID:             7,
+		TokenID:        "access-1",
+		SessionID:      "family-1",
+		TokenExpiresAt: expiresAt,
+	})
+
+	t.Run("выход из текущей сессии", func(t *testing.T) {
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(new(mockUserRepo), tokenRepo, cfg)
+
+		tokenRepo.On("RevokeToken", ctx, "access-1", expiresAt).Return(nil)
+		tokenRepo.On("RevokeRefreshFamily", ctx, "family-1").Return(nil)
+
+		err := uc.Logout(ctx)
+		assert.NoError(t, err)
+		tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("выход из всех сессий", func(t *testing.T) {
+		tokenRepo := new(mockTokenRepo)
+		uc := NewAuthUseCase(new(mockUserRepo), tokenRepo, cfg)
+
+		tokenRepo.On("RevokeUserTokens", ctx, int64(7), mock.AnythingOfType("time.Time")).Return(nil)
+
+		err := uc.LogoutAll(ctx)
+		assert.NoError(t, err)
+		tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("нет пользователя в контексте", func(t *testing.T) {
+		uc := NewAuthUseCase(new(mockUserRepo), new(mockTokenRepo), cfg)
+
+		assert.ErrorIs(t, uc.Logout(context.Background()), domain.ErrUnauthenticated)
+		assert.ErrorIs(t, uc.LogoutAll(context.Background()), domain.ErrUnauthenticated)
+	})
+}
@@ -2,38 +2,49 @@ package useCase
 
 import (
 	"context"
-	"log/slog"
 	"time"
 )
 
 type TaskBackRepository interface {
-	DeleteExpiredTasks(ctx context.Context) (int64, error)
+	DeleteExpiredTasks(ctx context.Context, overdueFor time.Duration) (int64, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
 }
+
+type TaskSnapshotRepository interface {
+	SnapshotDailyCounts(ctx context.Context, day time.Time) (int64, error)
+}
+
+// BackgroundJob фоновые задачи обслуживания. Расписание, таймауты и повторы задаёт планировщик (internal/scheduler)
 type BackgroundJob struct {
-	taskRepository TaskBackRepository
+	taskRepository     TaskBackRepository
+	snapshotRepository TaskSnapshotRepository
+	cleanupAfter       time.Duration
+	trashRetention     time.Duration
 }
 
-func NewBackgroundJob(taskRepository TaskBackRepository) *BackgroundJob {
+// NewBackgroundJob cleanupAfter — через сколько после срока задача перемещается в корзину,
+// trashRetention — сколько задача хранится в корзине
+func NewBackgroundJob(taskRepository TaskBackRepository, snapshotRepository TaskSnapshotRepository, cleanupAfter, trashRetention time.Duration) *BackgroundJob {
 	return &BackgroundJob{
-		taskRepository: taskRepository,
+		taskRepository:     taskRepository,
+		snapshotRepository: snapshotRepository,
+		cleanupAfter:       cleanupAfter,
+		trashRetention:     trashRetention,
 	}
 }
 
-func (b *BackgroundJob) StartTaskCleanup(taskRepo TaskBackRepository, tickerTime int) {
-	const op = "internal.useCase.background_jons.StartTaskCleanup"
-
-	ticker := time.NewTicker(time.Hour * time.Duration(tickerTime))
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			deletedCount, err := taskRepo.DeleteExpiredTasks(context.Background())
-			if err != nil {
-				slog.Error(op, "ошибка удаления просроченных задач", slog.String("err", err.Error()))
-			} else {
-				slog.Info("удаление просроченных задач прошло успешно", slog.Int64("deleted", deletedCount))
-			}
-		}
-	}
+// CleanupExpiredTasks перемещает в корзину задачи, срок которых истёк более cleanupAfter назад
+func (b *BackgroundJob) CleanupExpiredTasks(ctx context.Context) (int64, error) {
+	return b.taskRepository.DeleteExpiredTasks(ctx, b.cleanupAfter)
+}
+
+// PurgeTrash безвозвратно удаляет задачи, пролежавшие в корзине дольше trashRetention
+func (b *BackgroundJob) PurgeTrash(ctx context.Context) (int64, error) {
+	return b.taskRepository.PurgeTrash(ctx, b.trashRetention)
+}
+
+// SnapshotDailyCounts обновляет снимок количества задач за текущий день (UTC).
+// Последнее обновление за день остаётся его итоговым снимком для burndown
+func (b *BackgroundJob) SnapshotDailyCounts(ctx context.Context) (int64, error) {
+	return b.snapshotRepository.SnapshotDailyCounts(ctx, time.Now())
 }
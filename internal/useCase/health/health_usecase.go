@@ -0,0 +1,111 @@
+package health
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// checkTimeout ограничивает каждую проверку, чтобы зависшая зависимость не задерживала ответ оркестратору.
+// Переменная, а не константа, чтобы тесты могли сократить ожидание
+var checkTimeout = 2 * time.Second
+
+// Имена зависимостей в ответе /readyz
+const (
+	CheckPostgres   = "postgres"
+	CheckMigrations = "migrations"
+	CheckRedis      = "redis"
+)
+
+type DatabaseChecker interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+type TokenStoreChecker interface {
+	Ping(ctx context.Context) error
+}
+
+type HealthUseCase struct {
+	db            DatabaseChecker
+	tokens        TokenStoreChecker
+	schemaVersion uint // Версия последней миграции, с которой собрано приложение
+}
+
+func NewHealthUseCase(db DatabaseChecker, tokens TokenStoreChecker, schemaVersion uint) *HealthUseCase {
+	return &HealthUseCase{
+		db:            db,
+		tokens:        tokens,
+		schemaVersion: schemaVersion,
+	}
+}
+
+// Readiness проверяет зависимости параллельно; отказ любой из них означает, что сервис не готов.
+// Redis проверяется через клиент хранилища токенов: без него RequireAuth отвечает 503 на каждый защищённый запрос
+func (uc *HealthUseCase) Readiness(ctx context.Context) *domain.Readiness {
+	checks := map[string]func(ctx context.Context) error{
+		CheckPostgres:   uc.db.Ping,
+		CheckMigrations: uc.checkMigrations,
+		CheckRedis:      uc.tokens.Ping,
+	}
+
+	readiness := &domain.Readiness{Status: domain.HealthOK, Checks: make(map[string]*domain.DependencyCheck, len(checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			result := runCheck(ctx, check)
+			mu.Lock()
+			readiness.Checks[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, result := range readiness.Checks {
+		if result.Status != domain.HealthOK {
+			readiness.Status = domain.HealthFail
+		}
+	}
+
+	return readiness
+}
+
+// checkMigrations сверяет версию схемы в базе с последней встроенной миграцией
+func (uc *HealthUseCase) checkMigrations(ctx context.Context) error {
+	version, dirty, err := uc.db.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("миграция %d применена не полностью (dirty)", version)
+	}
+	if version != uc.schemaVersion {
+		return fmt.Errorf("версия схемы %d, ожидается %d", version, uc.schemaVersion)
+	}
+
+	return nil
+}
+
+// runCheck выполняет проверку с таймаутом checkTimeout
+func runCheck(ctx context.Context, check func(ctx context.Context) error) *domain.DependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := &domain.DependencyCheck{Status: domain.HealthOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = domain.HealthFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"GoTasker/internal/domain"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+	"time"
+)
+
+type stubDatabase struct {
+	pingErr error
+	version uint
+	dirty   bool
+}
+
+func (s *stubDatabase) Ping(ctx context.Context) error {
+	return s.pingErr
+}
+
+func (s *stubDatabase) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	if s.pingErr != nil {
+		return 0, false, s.pingErr
+	}
+	return s.version, s.dirty, nil
+}
+
+type stubTokenStore struct {
+	err  error
+	hang bool // Ждать отмены контекста, как при зависшем соединении
+}
+
+func (s *stubTokenStore) Ping(ctx context.Context) error {
+	if s.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.err
+}
+
+func TestHealthUseCase_Readiness(t *testing.T) {
+	tests := []struct {
+		name   string
+		db     *stubDatabase
+		tokens *stubTokenStore
+		status domain.HealthStatus
+		failed []string
+	}{
+		{
+			name:   "все зависимости доступны",
+			db:     &stubDatabase{version: 10},
+			tokens: &stubTokenStore{},
+			status: domain.HealthOK,
+		},
+		{
+			name:   "без Redis сервис не готов: не проверить отзыв токенов",
+			db:     &stubDatabase{version: 10},
+			tokens: &stubTokenStore{err: errors.New("connection refused")},
+			status: domain.HealthFail,
+			failed: []string{CheckRedis},
+		},
+		{
+			name:   "без PostgreSQL сервис не готов",
+			db:     &stubDatabase{pingErr: errors.New("connection refused")},
+			tokens: &stubTokenStore{},
+			status: domain.HealthFail,
+			failed: []string{CheckPostgres, CheckMigrations},
+		},
+		{
+			name:   "схема отстаёт от приложения",
+			db:     &stubDatabase{version: 9},
+			tokens: &stubTokenStore{},
+			status: domain.HealthFail,
+			failed: []string{CheckMigrations},
+		},
+		{
+			name:   "миграция применена не полностью",
+			db:     &stubDatabase{version: 10, dirty: true},
+			tokens: &stubTokenStore{err: errors.New("connection refused")},
+			status: domain.HealthFail,
+			failed: []string{CheckMigrations, CheckRedis},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readiness := NewHealthUseCase(tt.db, tt.tokens, 10).Readiness(context.Background())
+
+			assert.Equal(t, tt.status, readiness.Status)
+			require.Len(t, readiness.Checks, 3)
+			for name, check := range readiness.Checks {
+				if assert.Contains(t, []string{CheckPostgres, CheckMigrations, CheckRedis}, name) && !slices.Contains(tt.failed, name) {
+					assert.Equal(t, domain.HealthOK, check.Status, name)
+					assert.Empty(t, check.Error, name)
+				}
+			}
+			for _, name := range tt.failed {
+				assert.NotEqual(t, domain.HealthOK, readiness.Checks[name].Status, name)
+				assert.NotEmpty(t, readiness.Checks[name].Error, name)
+			}
+		})
+	}
+}
+
+func TestHealthUseCase_ReadinessTimeout(t *testing.T) {
+	defer func(prev time.Duration) { checkTimeout = prev }(checkTimeout)
+	checkTimeout = 20 * time.Millisecond
+
+	start := time.Now()
+	readiness := NewHealthUseCase(&stubDatabase{version: 10}, &stubTokenStore{hang: true}, 10).Readiness(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, domain.HealthFail, readiness.Status)
+	assert.Equal(t, domain.HealthFail, readiness.Checks[CheckRedis].Status)
+	assert.Contains(t, readiness.Checks[CheckRedis].Error, context.DeadlineExceeded.Error())
+}
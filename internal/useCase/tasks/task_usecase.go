@@ -2,8 +2,9 @@ package tasks
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
 	"context"
-	"fmt"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
@@ -11,103 +12,369 @@ import (
 
 type TaskPostgresRepo interface {
 	Create(ctx context.Context, task *domain.Task) error
-	Update(ctx context.Context, updates map[string]interface{}) error
-	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error)
+	GetByID(ctx context.Context, ownerID, id int64) (*domain.Task, error)
+	Update(ctx context.Context, ownerID, id, version int64, patch *domain.TaskPatch, check func(current *domain.Task) error) (*domain.Task, error)
+	Delete(ctx context.Context, ownerID, id int64) error
+	Restore(ctx context.Context, ownerID, id int64) (*domain.Task, error)
+	Purge(ctx context.Context, ownerID, id int64) error
+	GetAll(ctx context.Context, ownerID int64, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error)
+	Search(ctx context.Context, ownerID int64, query string, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskSearchPage, error)
 	ImportTasks(ctx context.Context, tasks []*domain.Task) (int, error)
+	History(ctx context.Context, ownerID, taskID int64) ([]*domain.TaskEvent, error)
+	DeleteExpiredTasks(ctx context.Context, overdueFor time.Duration) (int64, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// ChangePublisher получает уведомления об успешных изменениях задач
+type ChangePublisher interface {
+	PublishTaskChange(ctx context.Context, change domain.TaskChange)
 }
 
 type TaskUseCase struct {
 	taskRepository TaskPostgresRepo
+	workflow       *domain.Workflow
+	publisher      ChangePublisher
 }
 
-func NewTaskUseCase(taskRepository TaskPostgresRepo) *TaskUseCase {
+// NewTaskUseCase создаёт use case задач; publisher может быть nil, если изменения никому не нужны
+func NewTaskUseCase(taskRepository TaskPostgresRepo, workflow *domain.Workflow, publisher ChangePublisher) *TaskUseCase {
 	return &TaskUseCase{
 		taskRepository: taskRepository,
+		workflow:       workflow,
+		publisher:      publisher,
+	}
+}
+
+// publish сообщает подписчикам об изменении задач владельца ownerID
+func (uc *TaskUseCase) publish(ctx context.Context, ownerID int64, eventType domain.TaskEventType) {
+	if uc.publisher == nil {
+		return
 	}
+	uc.publisher.PublishTaskChange(ctx, domain.TaskChange{OwnerID: ownerID, Type: eventType})
 }
 
 func (uc *TaskUseCase) Create(ctx context.Context, task *domain.Task) error {
 	const op = "internal.useCase.task_useCase.Create"
 
-	if err := validateTask(task); err != nil {
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if task.Status == "" {
+		task.Status = uc.workflow.Initial()
+	}
+	if err := uc.validateTask(task); err != nil {
 		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
 		return err
 	}
 
+	task.OwnerID = user.ID
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
+	task.DeletedAt = nil
+
+	if err := uc.taskRepository.Create(ctx, task); err != nil {
+		return err
+	}
 
-	return uc.taskRepository.Create(ctx, task)
+	uc.publish(ctx, user.ID, domain.TaskEventCreated)
+	return nil
 }
 
-func (uc *TaskUseCase) Update(ctx context.Context, updatedTask *domain.Task) error {
-	const op = "internal.useCase.task_useCase.Update"
+func (uc *TaskUseCase) GetByID(ctx context.Context, id int64) (*domain.Task, error) {
+	const op = "internal.useCase.task_useCase.GetByID"
 
-	updates := make(map[string]interface{})
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
 
-	if updatedTask.Title != "" {
-		updates["title"] = updatedTask.Title
+	if id == 0 {
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
 	}
-	if updatedTask.Description != "" {
-		updates["description"] = updatedTask.Description
+
+	return uc.taskRepository.GetByID(ctx, user.ID, id)
+}
+
+// History возвращает историю изменений задачи, в том числе удалённой.
+// Если событий нет, задача никогда не принадлежала пользователю
+func (uc *TaskUseCase) History(ctx context.Context, id int64) ([]*domain.TaskEvent, error) {
+	const op = "internal.useCase.task_useCase.History"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
 	}
-	if updatedTask.Status != "" {
-		if !isValidStatus(string(updatedTask.Status)) {
-			err := fmt.Errorf("невалидный статус задачи: %s", updatedTask.Status)
-			slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
-			return err
-		}
-		updates["status"] = updatedTask.Status
+
+	if id == 0 {
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
 	}
-	if updatedTask.Priority != "" {
-		if !isValidPriority(string(updatedTask.Priority)) {
-			err := fmt.Errorf("невалидный приоритет задачи: %s", updatedTask.Priority)
-			slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
-			return err
-		}
-		updates["priority"] = updatedTask.Priority
+
+	events, err := uc.taskRepository.History(ctx, user.ID, id)
+	if err != nil {
+		return nil, err
 	}
-	if !updatedTask.DueDate.IsZero() {
-		updates["due_date"] = updatedTask.DueDate
+	if len(events) == 0 {
+		return nil, domain.NewTaskNotFoundError(id)
 	}
 
-	if len(updates) == 0 {
-		return fmt.Errorf("нет данных для обновления")
+	return events, nil
+}
+
+// Update полностью заменяет изменяемые поля задачи, если updatedTask.Version совпадает с текущей версией (0 — без проверки).
+// При расхождении версий возвращает *domain.VersionConflictError с актуальной задачей
+func (uc *TaskUseCase) Update(ctx context.Context, updatedTask *domain.Task) (*domain.Task, error) {
+	const op = "internal.useCase.task_useCase.Update"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
 	}
 
 	if updatedTask.ID == 0 {
-		err := fmt.Errorf("id задачи не может быть нулевым")
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
 		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
-		return err
+		return nil, err
+	}
+
+	if err := uc.validateTask(updatedTask); err != nil {
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
 	}
-	updates["id"] = updatedTask.ID
-	updates["updated_at"] = time.Now()
 
-	return uc.taskRepository.Update(ctx, updates)
+	return uc.update(ctx, user.ID, updatedTask.ID, updatedTask.Version, domain.FullTaskPatch(updatedTask))
 }
 
+// Patch изменяет только переданные в патче поля задачи, проверяя версию так же, как Update
+func (uc *TaskUseCase) Patch(ctx context.Context, id, version int64, patch *domain.TaskPatch) (*domain.Task, error) {
+	const op = "internal.useCase.task_useCase.Patch"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if id == 0 {
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	if err := uc.validatePatch(patch); err != nil {
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return uc.update(ctx, user.ID, id, version, patch)
+}
+
+// update применяет патч, проверяя смену статуса по автомату статусов.
+// Переход проверяется в репозитории по строке, которую блокирует сама запись,
+// поэтому отдельное чтение не нужно и If-Match: * не приводит к конфликту версий
+func (uc *TaskUseCase) update(ctx context.Context, ownerID, id, version int64, patch *domain.TaskPatch) (*domain.Task, error) {
+	const op = "internal.useCase.task_useCase.update"
+
+	var check func(current *domain.Task) error
+	if patch.Status != nil {
+		check = func(current *domain.Task) error {
+			if err := uc.workflow.CheckTransition(current.Status, *patch.Status); err != nil {
+				slog.Warn(op, "запрещённый переход статуса",
+					slog.Int64("task_id", id),
+					slog.String("from", string(current.Status)),
+					slog.String("to", string(*patch.Status)),
+				)
+				return err
+			}
+			return nil
+		}
+	}
+
+	task, err := uc.taskRepository.Update(ctx, ownerID, id, version, patch, check)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publish(ctx, ownerID, domain.TaskEventUpdated)
+	return task, nil
+}
+
+// Delete перемещает задачу в корзину; восстановить её можно через Restore
 func (uc *TaskUseCase) Delete(ctx context.Context, id int64) error {
 	const op = "internal.useCase.task_useCase.Delete"
 
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
 	if id == 0 {
-		err := fmt.Errorf("id задачи не может быть нулевым")
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
 		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
 		return err
 	}
 
-	return uc.taskRepository.Delete(ctx, id)
+	if err := uc.taskRepository.Delete(ctx, user.ID, id); err != nil {
+		return err
+	}
+
+	uc.publish(ctx, user.ID, domain.TaskEventTrashed)
+	return nil
+}
+
+// Purge безвозвратно удаляет задачу, в том числе из корзины
+func (uc *TaskUseCase) Purge(ctx context.Context, id int64) error {
+	const op = "internal.useCase.task_useCase.Purge"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if id == 0 {
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return err
+	}
+
+	if err := uc.taskRepository.Purge(ctx, user.ID, id); err != nil {
+		return err
+	}
+
+	uc.publish(ctx, user.ID, domain.TaskEventDeleted)
+	return nil
+}
+
+// Restore возвращает задачу из корзины
+func (uc *TaskUseCase) Restore(ctx context.Context, id int64) (*domain.Task, error) {
+	const op = "internal.useCase.task_useCase.Restore"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if id == 0 {
+		err := domain.NewFieldError("id", i18n.TaskIDRequired)
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	task, err := uc.taskRepository.Restore(ctx, user.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publish(ctx, user.ID, domain.TaskEventRestored)
+	return task, nil
+}
+
+// Trash возвращает страницу задач из корзины пользователя
+func (uc *TaskUseCase) Trash(ctx context.Context, page *domain.PageRequest) (*domain.TaskPage, error) {
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if page == nil {
+		page = domain.NewPageRequest(0, "", domain.DefaultTaskSort)
+	}
+
+	return uc.taskRepository.GetAll(ctx, user.ID, &domain.TaskFilter{InTrash: true}, page)
 }
 
-func (uc *TaskUseCase) GetAll(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error) {
+func (uc *TaskUseCase) GetAll(ctx context.Context, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error) {
 	const op = "internal.useCase.task_useCase.GetAll"
 
-	return uc.taskRepository.GetAll(ctx, filter)
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if filter != nil {
+		if err := filter.ValidateStatuses(uc.workflow); err != nil {
+			slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+			return nil, err
+		}
+	}
+
+	if page == nil {
+		page = domain.NewPageRequest(0, "", domain.DefaultTaskSort)
+	}
+
+	return uc.taskRepository.GetAll(ctx, user.ID, filter, page)
+}
+
+// Search выполняет полнотекстовый поиск по задачам пользователя
+func (uc *TaskUseCase) Search(ctx context.Context, query string, filter *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskSearchPage, error) {
+	const op = "internal.useCase.task_useCase.Search"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	query, err := domain.NormalizeSearchQuery(query)
+	if err != nil {
+		slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+		return nil, err
+	}
+	if filter != nil {
+		if err = filter.ValidateStatuses(uc.workflow); err != nil {
+			slog.Error(op, "ошибка валидации", slog.String("err", err.Error()))
+			return nil, err
+		}
+	}
+
+	if page == nil {
+		page = domain.NewPageRequest(0, "", domain.DefaultTaskSort)
+	}
+
+	return uc.taskRepository.Search(ctx, user.ID, query, filter, page)
+}
+
+// Export постранично выгружает все задачи пользователя, передавая каждую страницу в yield
+func (uc *TaskUseCase) Export(ctx context.Context, yield func(tasks []*domain.Task) error) error {
+	const op = "internal.useCase.task_useCase.Export"
+
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	page := domain.NewPageRequest(domain.MaxPageLimit, "", domain.DefaultTaskSort)
+	page.SkipTotal = true
+
+	for {
+		result, err := uc.taskRepository.GetAll(ctx, user.ID, &domain.TaskFilter{}, page)
+		if err != nil {
+			slog.Error(op, "ошибка выгрузки задач", slog.String("err", err.Error()))
+			return err
+		}
+
+		if err = yield(result.Items); err != nil {
+			return err
+		}
+
+		if result.NextCursor == "" {
+			return nil
+		}
+		page.Cursor = result.NextCursor
+	}
 }
 
 func (uc *TaskUseCase) Import(ctx context.Context, tasks []*domain.Task) (int, []string, error) {
 	const op = "internal.useCase.task_useCase.Import"
 
+	user, ok := domain.UserFromContext(ctx)
+	if !ok {
+		return 0, nil, domain.ErrUnauthenticated
+	}
+
 	var validTasks []*domain.Task
 	var invalidTasks []string
 	var wg sync.WaitGroup
@@ -123,7 +390,10 @@ func (uc *TaskUseCase) Import(ctx context.Context, tasks []*domain.Task) (int, [
 		go func(i int, task *domain.Task) {
 			defer wg.Done()
 			// Проверка валидности задачи
-			err := validateTask(task)
+			if task.Status == "" {
+				task.Status = uc.workflow.Initial()
+			}
+			err := uc.validateTask(task)
 			taskChan <- struct {
 				task  *domain.Task
 				err   error
@@ -137,10 +407,12 @@ func (uc *TaskUseCase) Import(ctx context.Context, tasks []*domain.Task) (int, [
 		close(taskChan)
 	}()
 
+	lang := i18n.FromContext(ctx)
 	for result := range taskChan {
 		if result.err != nil {
-			invalidTasks = append(invalidTasks, fmt.Sprintf("Задача %d: %s", result.index+1, result.err.Error()))
+			invalidTasks = append(invalidTasks, i18n.T(lang, i18n.ImportTaskRejected, result.index+1, localizeError(result.err, lang)))
 		} else {
+			result.task.OwnerID = user.ID
 			result.task.CreatedAt = time.Now()
 			result.task.UpdatedAt = time.Now()
 			validTasks = append(validTasks, result.task)
@@ -148,7 +420,9 @@ func (uc *TaskUseCase) Import(ctx context.Context, tasks []*domain.Task) (int, [
 	}
 
 	if len(validTasks) == 0 {
-		return 0, invalidTasks, fmt.Errorf("все задачи невалидны")
+		err := domain.NewError(domain.ErrValidation, domain.CodeImportNoValidTasks, i18n.ImportNoValidTasks)
+		err.Details = invalidTasks
+		return 0, invalidTasks, err
 	}
 
 	inserted, err := uc.taskRepository.ImportTasks(ctx, validTasks)
@@ -156,41 +430,81 @@ func (uc *TaskUseCase) Import(ctx context.Context, tasks []*domain.Task) (int, [
 		return 0, invalidTasks, err
 	}
 
+	uc.publish(ctx, user.ID, domain.TaskEventImported)
 	return inserted, invalidTasks, nil
 }
 
-func validateTask(task *domain.Task) error {
+// DeleteExpiredTasks перемещает в корзину задачи всех пользователей, срок которых истёк более overdueFor назад
+func (uc *TaskUseCase) DeleteExpiredTasks(ctx context.Context, overdueFor time.Duration) (int64, error) {
+	count, err := uc.taskRepository.DeleteExpiredTasks(ctx, overdueFor)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		uc.publish(ctx, 0, domain.TaskEventTrashed)
+	}
+	return count, nil
+}
+
+// PurgeTrash безвозвратно удаляет задачи, пролежавшие в корзине дольше retention.
+// Задачи в корзине не входят в аналитику, поэтому об удалении никого не уведомляем
+func (uc *TaskUseCase) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return uc.taskRepository.PurgeTrash(ctx, retention)
+}
+
+// localizeError возвращает текст доменной ошибки на языке lang
+func localizeError(err error, lang i18n.Lang) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Localize(lang)
+	}
+	return err.Error()
+}
+
+func (uc *TaskUseCase) validateTask(task *domain.Task) error {
 	if task.Title == "" {
-		return fmt.Errorf("название задачи не может быть пустым")
+		return domain.NewFieldError("title", i18n.TaskTitleRequired)
 	}
 	if task.Priority == "" {
-		return fmt.Errorf("приоритет задачи не может быть пустым")
+		return domain.NewFieldError("priority", i18n.TaskPriorityRequired)
 	}
 	if task.DueDate.IsZero() {
-		return fmt.Errorf("не указана дата завершения задачи")
+		return domain.NewFieldError("due_date", i18n.TaskDueDateRequired)
 	}
-	if task.Status != "" && task.Status != "pending" && task.Status != "in_progress" && task.Status != "done" {
-		return fmt.Errorf("невалидный статус задачи")
+	if task.Status == "" {
+		return domain.NewFieldError("status", i18n.TaskStatusRequired)
 	}
-	if !isValidStatus(string(task.Status)) {
-		return fmt.Errorf("некорректный статус задачи: %s", task.Status)
+	if !uc.workflow.IsValid(task.Status) {
+		return domain.NewFieldError("status", i18n.TaskInvalidStatus, task.Status)
 	}
 
 	if !isValidPriority(string(task.Priority)) {
-		return fmt.Errorf("некорректный приоритет задачи: %s", task.Priority)
+		return domain.NewFieldError("priority", i18n.TaskInvalidPriority, task.Priority)
 	}
 
 	return nil
 }
 
-func isValidStatus(status string) bool {
-	validStatuses := []string{"pending", "in_progress", "done"}
-	for _, s := range validStatuses {
-		if status == s {
-			return true
-		}
+// validatePatch проверяет только те поля, которые меняет патч
+func (uc *TaskUseCase) validatePatch(patch *domain.TaskPatch) error {
+	if patch.IsEmpty() {
+		return domain.NewValidationError(i18n.PatchEmpty)
 	}
-	return false
+	if patch.Title != nil && *patch.Title == "" {
+		return domain.NewFieldError("title", i18n.TaskTitleRequired)
+	}
+	if patch.Status != nil && !uc.workflow.IsValid(*patch.Status) {
+		return domain.NewFieldError("status", i18n.TaskInvalidStatus, *patch.Status)
+	}
+	if patch.Priority != nil && !isValidPriority(string(*patch.Priority)) {
+		return domain.NewFieldError("priority", i18n.TaskInvalidPriority, *patch.Priority)
+	}
+	if patch.DueDate != nil && patch.DueDate.IsZero() {
+		return domain.NewFieldError("due_date", i18n.TaskDueDateRequired)
+	}
+
+	return nil
 }
 
 func isValidPriority(priority string) bool {
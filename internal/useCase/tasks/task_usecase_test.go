@@ -2,10 +2,13 @@ package tasks
 
 import (
 	"GoTasker/internal/domain"
+	"GoTasker/internal/i18n"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
 )
@@ -19,19 +22,60 @@ func (m *mockTaskRepo) Create(ctx context.Context, task *domain.Task) error {
 	return args.Error(0)
 }
 
-func (m *mockTaskRepo) Update(ctx context.Context, updates map[string]interface{}) error {
-	args := m.Called(ctx, updates)
+func (m *mockTaskRepo) GetByID(ctx context.Context, ownerID, id int64) (*domain.Task, error) {
+	args := m.Called(ctx, ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+// Update третьим возвращаемым значением принимает задачу до изменения, по которой выполняется check
+func (m *mockTaskRepo) Update(ctx context.Context, ownerID, id, version int64, patch *domain.TaskPatch, check func(current *domain.Task) error) (*domain.Task, error) {
+	args := m.Called(ctx, ownerID, id, version, patch)
+	if len(args) > 2 && check != nil {
+		if err := check(args.Get(2).(*domain.Task)); err != nil {
+			return nil, err
+		}
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *mockTaskRepo) Delete(ctx context.Context, ownerID, id int64) error {
+	args := m.Called(ctx, ownerID, id)
 	return args.Error(0)
 }
 
-func (m *mockTaskRepo) Delete(ctx context.Context, id int64) error {
-	args := m.Called(ctx, id)
+func (m *mockTaskRepo) Restore(ctx context.Context, ownerID, id int64) (*domain.Task, error) {
+	args := m.Called(ctx, ownerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *mockTaskRepo) Purge(ctx context.Context, ownerID, id int64) error {
+	args := m.Called(ctx, ownerID, id)
 	return args.Error(0)
 }
 
-func (m *mockTaskRepo) GetAll(ctx context.Context, f *domain.TaskFilter) ([]*domain.Task, error) {
-	args := m.Called(ctx, f)
-	return args.Get(0).([]*domain.Task), args.Error(1)
+func (m *mockTaskRepo) GetAll(ctx context.Context, ownerID int64, f *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskPage, error) {
+	args := m.Called(ctx, ownerID, f, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TaskPage), args.Error(1)
+}
+
+func (m *mockTaskRepo) Search(ctx context.Context, ownerID int64, query string, f *domain.TaskFilter, page *domain.PageRequest) (*domain.TaskSearchPage, error) {
+	args := m.Called(ctx, ownerID, query, f, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TaskSearchPage), args.Error(1)
 }
 
 func (m *mockTaskRepo) ImportTasks(ctx context.Context, tasks []*domain.Task) (int, error) {
@@ -39,10 +83,43 @@ func (m *mockTaskRepo) ImportTasks(ctx context.Context, tasks []*domain.Task) (i
 	return args.Int(0), args.Error(1)
 }
 
+func (m *mockTaskRepo) History(ctx context.Context, ownerID, taskID int64) ([]*domain.TaskEvent, error) {
+	args := m.Called(ctx, ownerID, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TaskEvent), args.Error(1)
+}
+
+func (m *mockTaskRepo) DeleteExpiredTasks(ctx context.Context, overdueFor time.Duration) (int64, error) {
+	args := m.Called(ctx, overdueFor)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockTaskRepo) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	args := m.Called(ctx, retention)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// recordingPublisher запоминает опубликованные изменения задач
+type recordingPublisher struct {
+	changes []domain.TaskChange
+}
+
+func (p *recordingPublisher) PublishTaskChange(ctx context.Context, change domain.TaskChange) {
+	p.changes = append(p.changes, change)
+}
+
+const testUserID int64 = 7
+
+func userContext() context.Context {
+	return domain.ContextWithUser(context.Background(), &domain.AuthUser{ID: testUserID, Username: "john"})
+}
+
 func TestTaskUseCase_Create(t *testing.T) {
-	ctx := context.Background()
+	ctx := userContext()
 	mockRepo := new(mockTaskRepo)
-	uc := NewTaskUseCase(mockRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
 
 	t.Run("успешное создание задачи", func(t *testing.T) {
 		task := &domain.Task{
@@ -55,9 +132,22 @@ func TestTaskUseCase_Create(t *testing.T) {
 
 		err := uc.Create(ctx, task)
 		assert.NoError(t, err)
+		assert.Equal(t, testUserID, task.OwnerID)
 		mockRepo.AssertCalled(t, "Create", ctx, mock.AnythingOfType("*domain.Task"))
 	})
 
+	t.Run("ошибка - нет пользователя в контексте", func(t *testing.T) {
+		task := &domain.Task{
+			Title:    "Test Task",
+			Priority: "high",
+			Status:   "pending",
+			DueDate:  time.Now().Add(24 * time.Hour),
+		}
+
+		err := uc.Create(context.Background(), task)
+		assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+	})
+
 	t.Run("ошибка валидации - пустой title", func(t *testing.T) {
 		task := &domain.Task{
 			Title:    "",
@@ -84,9 +174,9 @@ func TestTaskUseCase_Create(t *testing.T) {
 }
 
 func TestTaskUseCase_Update(t *testing.T) {
-	ctx := context.Background()
+	ctx := userContext()
 	mockRepo := new(mockTaskRepo)
-	uc := NewTaskUseCase(mockRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
 
 	t.Run("успешное обновление задачи", func(t *testing.T) {
 		task := &domain.Task{
@@ -96,11 +186,42 @@ func TestTaskUseCase_Update(t *testing.T) {
 			Status:   "in_progress",
 			DueDate:  time.Now().Add(48 * time.Hour),
 		}
-		mockRepo.On("Update", ctx, mock.Anything).Return(nil)
+		current := &domain.Task{ID: 1, OwnerID: testUserID, Status: domain.StatusPending, Version: 5}
+		stored := &domain.Task{ID: 1, OwnerID: testUserID, Title: "Updated Task", UpdatedAt: time.Now()}
+		mockRepo.On("Update", ctx, testUserID, int64(1), int64(0), mock.Anything).Return(stored, nil, current).Once()
 
-		err := uc.Update(ctx, task)
+		updated, err := uc.Update(ctx, task)
 		assert.NoError(t, err)
-		mockRepo.AssertCalled(t, "Update", ctx, mock.Anything)
+		assert.Equal(t, stored, updated)
+		mockRepo.AssertCalled(t, "Update", ctx, testUserID, int64(1), int64(0), domain.FullTaskPatch(task))
+		mockRepo.AssertNotCalled(t, "GetByID", ctx, testUserID, int64(1))
+	})
+
+	t.Run("полная замена требует обязательные поля", func(t *testing.T) {
+		_, err := uc.Update(ctx, &domain.Task{ID: 1, Description: "только описание"})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("конфликт версий", func(t *testing.T) {
+		task := &domain.Task{
+			ID:       1,
+			Title:    "Updated Task",
+			Priority: "medium",
+			Status:   "pending",
+			DueDate:  time.Now().Add(48 * time.Hour),
+			Version:  2,
+		}
+		current := &domain.Task{ID: 1, OwnerID: testUserID, Title: "Other Title", Status: domain.StatusPending, Version: 3}
+		mockRepo.On("Update", ctx, testUserID, int64(1), int64(2), mock.Anything).
+			Return(nil, &domain.VersionConflictError{Current: current}).Once()
+
+		_, err := uc.Update(ctx, task)
+		assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+
+		var conflict *domain.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, current, conflict.Current)
 	})
 
 	t.Run("ошибка валидации - пустой ID", func(t *testing.T) {
@@ -111,7 +232,7 @@ func TestTaskUseCase_Update(t *testing.T) {
 			DueDate:  time.Now().Add(48 * time.Hour),
 		}
 
-		err := uc.Update(ctx, task)
+		_, err := uc.Update(ctx, task)
 		assert.ErrorContains(t, err, "id задачи не может быть нулевым")
 	})
 
@@ -124,22 +245,210 @@ func TestTaskUseCase_Update(t *testing.T) {
 			DueDate:  time.Now().Add(48 * time.Hour),
 		}
 
-		err := uc.Update(ctx, task)
+		_, err := uc.Update(ctx, task)
 		assert.ErrorContains(t, err, "невалидный статус задачи")
 	})
 }
 
+func TestTaskUseCase_Patch(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
+
+	t.Run("очистка описания", func(t *testing.T) {
+		patch, err := domain.ParseTaskMergePatch([]byte(`{"description": null, "status": "done"}`))
+		require.NoError(t, err)
+		require.NotNil(t, patch.Description)
+		assert.Equal(t, "", *patch.Description)
+		assert.Nil(t, patch.Title)
+
+		current := &domain.Task{ID: 1, OwnerID: testUserID, Status: domain.StatusInProgress, Version: 3}
+		stored := &domain.Task{ID: 1, OwnerID: testUserID, Status: domain.StatusDone, Version: 4}
+		mockRepo.On("Update", ctx, testUserID, int64(1), int64(3), patch).Return(stored, nil, current).Once()
+
+		updated, err := uc.Patch(ctx, 1, 3, patch)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, updated)
+	})
+
+	t.Run("обязательное поле нельзя очистить", func(t *testing.T) {
+		_, err := domain.ParseTaskMergePatch([]byte(`{"title": null}`))
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("задача из ответа GET целиком", func(t *testing.T) {
+		startedAt := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+		completedAt := startedAt.Add(3 * time.Hour)
+		task := &domain.Task{
+			ID:          1,
+			OwnerID:     testUserID,
+			Title:       "Task",
+			Status:      domain.StatusDone,
+			Priority:    domain.PriorityHigh,
+			DueDate:     startedAt.Add(24 * time.Hour),
+			CreatedAt:   startedAt.Add(-time.Hour),
+			UpdatedAt:   completedAt,
+			Version:     4,
+			StartedAt:   &startedAt,
+			CompletedAt: &completedAt,
+		}
+		data, err := json.Marshal(task)
+		require.NoError(t, err)
+
+		patch, err := domain.ParseTaskMergePatch(data)
+		require.NoError(t, err)
+		assert.Equal(t, "Task", *patch.Title)
+		assert.Equal(t, domain.StatusDone, *patch.Status)
+		assert.Equal(t, domain.PriorityHigh, *patch.Priority)
+	})
+
+	t.Run("неизвестное поле", func(t *testing.T) {
+		_, err := domain.ParseTaskMergePatch([]byte(`{"owner": 5}`))
+		assert.ErrorContains(t, err, "неизвестное поле задачи: owner")
+	})
+
+	t.Run("невалидный приоритет", func(t *testing.T) {
+		priority := domain.Priority("urgent")
+		_, err := uc.Patch(ctx, 1, 3, &domain.TaskPatch{Priority: &priority})
+		assert.ErrorContains(t, err, "некорректный приоритет задачи: urgent")
+
+		var domainErr *domain.Error
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, "priority", domainErr.Field)
+		assert.Equal(t, "invalid task priority: urgent", domainErr.Localize(i18n.EN))
+	})
+
+	t.Run("пустой патч", func(t *testing.T) {
+		_, err := uc.Patch(ctx, 1, 3, &domain.TaskPatch{})
+		assert.ErrorContains(t, err, "нет данных для обновления")
+	})
+}
+
+func TestTaskUseCase_StatusTransitions(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
+
+	status := func(s domain.Status) *domain.TaskPatch {
+		return &domain.TaskPatch{Status: &s}
+	}
+
+	t.Run("запрещённый переход", func(t *testing.T) {
+		done := &domain.Task{ID: 1, OwnerID: testUserID, Status: domain.StatusDone, Version: 2}
+		mockRepo.On("Update", ctx, testUserID, int64(1), int64(0), mock.Anything).Return(nil, nil, done).Once()
+
+		_, err := uc.Patch(ctx, 1, 0, status(domain.StatusPending))
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		var domainErr *domain.Error
+		require.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, domain.CodeInvalidTransition, domainErr.Code)
+		assert.Equal(t, []string{"in_progress"}, domainErr.Details)
+	})
+
+	t.Run("If-Match: * не превращается в проверку версии", func(t *testing.T) {
+		done := &domain.Task{ID: 2, OwnerID: testUserID, Status: domain.StatusDone, Version: 7}
+		patch := status(domain.StatusInProgress)
+		mockRepo.On("Update", ctx, testUserID, int64(2), int64(0), patch).Return(&domain.Task{ID: 2, Version: 8}, nil, done).Once()
+
+		_, err := uc.Patch(ctx, 2, 0, patch)
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "GetByID", ctx, testUserID, int64(2))
+	})
+
+	t.Run("пользовательские статусы", func(t *testing.T) {
+		rules, err := domain.ParseStatusTransitions("pending:in_progress;in_progress:review,blocked;blocked:in_progress;review:done,in_progress;done:")
+		require.NoError(t, err)
+		workflow, err := domain.NewWorkflow(domain.StatusPending, rules)
+		require.NoError(t, err)
+
+		custom := NewTaskUseCase(mockRepo, workflow, nil)
+		inProgress := &domain.Task{ID: 3, OwnerID: testUserID, Status: domain.StatusInProgress, Version: 1}
+		mockRepo.On("Update", ctx, testUserID, int64(3), int64(1), mock.Anything).Return(&domain.Task{ID: 3}, nil, inProgress)
+
+		_, err = custom.Patch(ctx, 3, 1, status("review"))
+		assert.NoError(t, err)
+
+		_, err = custom.Patch(ctx, 3, 1, status(domain.StatusDone))
+		assert.ErrorIs(t, err, domain.ErrConflict)
+
+		_, err = uc.Patch(ctx, 3, 1, status("review"))
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestTaskUseCase_GetByID(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
+
+	t.Run("успешное получение задачи", func(t *testing.T) {
+		expected := &domain.Task{ID: 1, OwnerID: testUserID, Title: "Task 1"}
+		mockRepo.On("GetByID", ctx, testUserID, int64(1)).Return(expected, nil)
+
+		task, err := uc.GetByID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, task)
+	})
+
+	t.Run("задача не найдена", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, testUserID, int64(2)).Return(nil, domain.NewTaskNotFoundError(2))
+
+		task, err := uc.GetByID(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, task)
+	})
+
+	t.Run("ошибка валидации - нулевой ID", func(t *testing.T) {
+		_, err := uc.GetByID(ctx, 0)
+		assert.ErrorContains(t, err, "id задачи не может быть нулевым")
+	})
+}
+
+func TestTaskUseCase_History(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
+
+	t.Run("успешное получение истории", func(t *testing.T) {
+		expected := []*domain.TaskEvent{
+			{ID: 1, TaskID: 1, Type: domain.TaskEventCreated},
+			{ID: 2, TaskID: 1, Type: domain.TaskEventStatusChanged},
+		}
+		mockRepo.On("History", ctx, testUserID, int64(1)).Return(expected, nil)
+
+		events, err := uc.History(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, events)
+	})
+
+	t.Run("нет событий - задача не найдена", func(t *testing.T) {
+		mockRepo.On("History", ctx, testUserID, int64(2)).Return([]*domain.TaskEvent{}, nil)
+
+		events, err := uc.History(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+		assert.Nil(t, events)
+	})
+
+	t.Run("ошибка валидации - нулевой ID", func(t *testing.T) {
+		_, err := uc.History(ctx, 0)
+		assert.ErrorContains(t, err, "id задачи не может быть нулевым")
+	})
+}
+
 func TestTaskUseCase_Delete(t *testing.T) {
-	ctx := context.Background()
+	ctx := userContext()
 	mockRepo := new(mockTaskRepo)
-	uc := NewTaskUseCase(mockRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
 
 	t.Run("успешное удаление задачи", func(t *testing.T) {
-		mockRepo.On("Delete", ctx, int64(1)).Return(nil)
+		mockRepo.On("Delete", ctx, testUserID, int64(1)).Return(nil)
 
 		err := uc.Delete(ctx, 1)
 		assert.NoError(t, err)
-		mockRepo.AssertCalled(t, "Delete", ctx, int64(1))
+		mockRepo.AssertCalled(t, "Delete", ctx, testUserID, int64(1))
 	})
 
 	t.Run("ошибка валидации - нулевой ID", func(t *testing.T) {
@@ -148,28 +457,189 @@ func TestTaskUseCase_Delete(t *testing.T) {
 	})
 }
 
+func TestTaskUseCase_PublishChanges(t *testing.T) {
+	ctx := userContext()
+
+	t.Run("успешные изменения публикуются с владельцем задачи", func(t *testing.T) {
+		mockRepo := new(mockTaskRepo)
+		publisher := &recordingPublisher{}
+		uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), publisher)
+
+		mockRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockRepo.On("Delete", ctx, testUserID, int64(1)).Return(nil)
+		mockRepo.On("Restore", ctx, testUserID, int64(1)).Return(&domain.Task{ID: 1}, nil)
+		mockRepo.On("Purge", ctx, testUserID, int64(1)).Return(nil)
+
+		require.NoError(t, uc.Create(ctx, &domain.Task{Title: "t", Priority: domain.PriorityLow, DueDate: time.Now()}))
+		require.NoError(t, uc.Delete(ctx, 1))
+		_, err := uc.Restore(ctx, 1)
+		require.NoError(t, err)
+		require.NoError(t, uc.Purge(ctx, 1))
+
+		assert.Equal(t, []domain.TaskChange{
+			{OwnerID: testUserID, Type: domain.TaskEventCreated},
+			{OwnerID: testUserID, Type: domain.TaskEventTrashed},
+			{OwnerID: testUserID, Type: domain.TaskEventRestored},
+			{OwnerID: testUserID, Type: domain.TaskEventDeleted},
+		}, publisher.changes)
+	})
+
+	t.Run("ошибка записи не публикуется", func(t *testing.T) {
+		mockRepo := new(mockTaskRepo)
+		publisher := &recordingPublisher{}
+		uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), publisher)
+
+		mockRepo.On("Delete", ctx, testUserID, int64(1)).Return(domain.NewTaskNotFoundError(1))
+
+		assert.Error(t, uc.Delete(ctx, 1))
+		assert.Empty(t, publisher.changes)
+	})
+
+	t.Run("очистка просроченных сбрасывает аналитику всех пользователей", func(t *testing.T) {
+		mockRepo := new(mockTaskRepo)
+		publisher := &recordingPublisher{}
+		uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), publisher)
+
+		week := 7 * 24 * time.Hour
+		mockRepo.On("DeleteExpiredTasks", ctx, week).Return(int64(0), nil).Once()
+		mockRepo.On("DeleteExpiredTasks", ctx, week).Return(int64(3), nil).Once()
+
+		_, err := uc.DeleteExpiredTasks(ctx, week)
+		require.NoError(t, err)
+		assert.Empty(t, publisher.changes, "без перемещённых задач нечего сбрасывать")
+
+		count, err := uc.DeleteExpiredTasks(ctx, week)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		assert.Equal(t, []domain.TaskChange{{OwnerID: 0, Type: domain.TaskEventTrashed}}, publisher.changes)
+	})
+}
+
+func TestTaskUseCase_Trash(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
+
+	t.Run("корзина запрашивается отдельным фильтром", func(t *testing.T) {
+		page := domain.NewPageRequest(10, "", domain.DefaultTaskSort)
+		expected := &domain.TaskPage{Items: []*domain.Task{{ID: 1}}, Total: 1}
+		mockRepo.On("GetAll", ctx, testUserID, &domain.TaskFilter{InTrash: true}, page).Return(expected, nil)
+
+		result, err := uc.Trash(ctx, page)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("успешное восстановление задачи", func(t *testing.T) {
+		expected := &domain.Task{ID: 1, OwnerID: testUserID, Version: 3}
+		mockRepo.On("Restore", ctx, testUserID, int64(1)).Return(expected, nil)
+
+		task, err := uc.Restore(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, task)
+	})
+
+	t.Run("восстановление задачи не из корзины", func(t *testing.T) {
+		mockRepo.On("Restore", ctx, testUserID, int64(2)).Return(nil, domain.NewTaskNotFoundError(2))
+
+		_, err := uc.Restore(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrTaskNotFound)
+	})
+
+	t.Run("безвозвратное удаление", func(t *testing.T) {
+		mockRepo.On("Purge", ctx, testUserID, int64(1)).Return(nil)
+
+		err := uc.Purge(ctx, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "Delete", ctx, testUserID, int64(1))
+	})
+
+	t.Run("ошибка валидации - нулевой ID", func(t *testing.T) {
+		_, err := uc.Restore(ctx, 0)
+		assert.ErrorContains(t, err, "id задачи не может быть нулевым")
+
+		err = uc.Purge(ctx, 0)
+		assert.ErrorContains(t, err, "id задачи не может быть нулевым")
+	})
+}
+
 func TestTaskUseCase_GetAll(t *testing.T) {
-	ctx := context.Background()
+	ctx := userContext()
 	mockRepo := new(mockTaskRepo)
-	uc := NewTaskUseCase(mockRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
 
 	t.Run("успешное получение всех задач", func(t *testing.T) {
-		mockRepo.On("GetAll", ctx, mock.Anything).Return([]*domain.Task{
-			{ID: 1, Title: "Task 1"},
-			{ID: 2, Title: "Task 2"},
-		}, nil)
+		mockRepo.On("GetAll", ctx, testUserID, mock.Anything, mock.Anything).Return(&domain.TaskPage{
+			Items: []*domain.Task{
+				{ID: 1, Title: "Task 1"},
+				{ID: 2, Title: "Task 2"},
+			},
+			Total: 2,
+		}, nil).Once()
+
+		page, err := uc.GetAll(ctx, &domain.TaskFilter{}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		mockRepo.AssertCalled(t, "GetAll", ctx, testUserID, mock.Anything,
+			domain.NewPageRequest(domain.DefaultPageLimit, "", domain.DefaultTaskSort))
+	})
+}
+
+func TestTaskUseCase_Search(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
 
-		tasks, err := uc.GetAll(ctx, &domain.TaskFilter{})
+	t.Run("запрос передаётся без лишних пробелов", func(t *testing.T) {
+		mockRepo.On("Search", ctx, testUserID, "отчёт", mock.Anything, mock.Anything).
+			Return(&domain.TaskSearchPage{Items: []*domain.TaskSearchResult{}}, nil).Once()
+
+		_, err := uc.Search(ctx, "  отчёт ", &domain.TaskFilter{}, nil)
 		assert.NoError(t, err)
-		assert.Len(t, tasks, 2)
-		mockRepo.AssertCalled(t, "GetAll", ctx, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("пустой запрос", func(t *testing.T) {
+		_, err := uc.Search(ctx, "   ", &domain.TaskFilter{}, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidSearchQuery)
+	})
+}
+
+func TestTaskUseCase_Export(t *testing.T) {
+	ctx := userContext()
+	mockRepo := new(mockTaskRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
+
+	t.Run("выгрузка проходит по всем страницам", func(t *testing.T) {
+		mockRepo.On("GetAll", ctx, testUserID, mock.Anything, mock.MatchedBy(func(p *domain.PageRequest) bool {
+			return p.Cursor == "" && p.SkipTotal
+		})).Return(&domain.TaskPage{
+			Items:      []*domain.Task{{ID: 1}, {ID: 2}},
+			NextCursor: "next",
+		}, nil).Once()
+		mockRepo.On("GetAll", ctx, testUserID, mock.Anything, mock.MatchedBy(func(p *domain.PageRequest) bool {
+			return p.Cursor == "next"
+		})).Return(&domain.TaskPage{
+			Items: []*domain.Task{{ID: 3}},
+		}, nil).Once()
+
+		var ids []int64
+		err := uc.Export(ctx, func(tasks []*domain.Task) error {
+			for _, task := range tasks {
+				ids = append(ids, task.ID)
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, ids)
+		mockRepo.AssertExpectations(t)
 	})
 }
 
 func TestTaskUseCase_Import(t *testing.T) {
-	ctx := context.Background()
+	ctx := userContext()
 	mockRepo := new(mockTaskRepo)
-	uc := NewTaskUseCase(mockRepo)
+	uc := NewTaskUseCase(mockRepo, domain.DefaultWorkflow(), nil)
 
 	t.Run("успешный импорт задач", func(t *testing.T) {
 		tasks := []*domain.Task{
@@ -193,6 +663,9 @@ func TestTaskUseCase_Import(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 2, inserted)
 		assert.Len(t, invalidTasks, 0)
+		for _, task := range tasks {
+			assert.Equal(t, testUserID, task.OwnerID)
+		}
 		mockRepo.AssertExpectations(t)
 	})
 
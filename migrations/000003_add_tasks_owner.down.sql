@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_owner_created;
+ALTER TABLE tasks DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,16 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+-- Задачи, созданные до появления владельцев, передаются первому зарегистрированному пользователю:
+-- без владельца их не увидит никто. Если пользователей нет, миграция прерывается, чтобы задачи не потерялись
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM tasks WHERE owner_id IS NULL) THEN
+        IF NOT EXISTS (SELECT 1 FROM users) THEN
+            RAISE EXCEPTION 'в tasks есть задачи без владельца, а пользователей нет: зарегистрируйте пользователя и повторите миграцию';
+        END IF;
+        UPDATE tasks SET owner_id = (SELECT MIN(id) FROM users) WHERE owner_id IS NULL;
+    END IF;
+END $$;
+
+ALTER TABLE tasks ALTER COLUMN owner_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_created ON tasks (owner_id, created_at DESC);
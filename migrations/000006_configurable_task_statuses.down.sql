@@ -0,0 +1,4 @@
+-- Пользовательские статусы (review, blocked, ...) считаются задачами в работе
+UPDATE tasks SET status = 'in_progress' WHERE status NOT IN ('pending', 'in_progress', 'done');
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_format_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK (status IN ('pending', 'in_progress', 'done'));
@@ -0,0 +1,4 @@
+-- Набор статусов и переходы между ними задаются конфигурацией приложения (TASK_STATUS_TRANSITIONS),
+-- поэтому в базе проверяется только формат имени статуса
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_format_check CHECK (status ~ '^[a-z][a-z0-9_]*$');
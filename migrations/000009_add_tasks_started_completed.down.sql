@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS tasks_track_status_times ON tasks;
+DROP FUNCTION IF EXISTS tasks_track_status_times();
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS started_at;
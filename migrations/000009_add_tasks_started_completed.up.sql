@@ -0,0 +1,32 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- Для уже выполненных задач точное время неизвестно, ближайшее приближение — последнее изменение
+UPDATE tasks SET completed_at = updated_at WHERE status = 'done' AND completed_at IS NULL;
+
+-- started_at — первый перевод в in_progress, completed_at — перевод в done.
+-- Возврат задачи из done сбрасывает completed_at, started_at сохраняется
+CREATE OR REPLACE FUNCTION tasks_track_status_times() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.status = 'in_progress' AND NEW.started_at IS NULL THEN
+        NEW.started_at := NOW();
+    END IF;
+
+    IF NEW.status = 'done' THEN
+        NEW.completed_at := NOW();
+    ELSE
+        NEW.completed_at := NULL;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tasks_track_status_times ON tasks;
+CREATE TRIGGER tasks_track_status_times
+    BEFORE INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_track_status_times();
@@ -0,0 +1,9 @@
+-- Количество задач по статусам на конец каждого дня, по владельцу и приоритету. Заполняется фоновой задачей
+CREATE TABLE IF NOT EXISTS task_daily_snapshots (
+    snapshot_date DATE NOT NULL,
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    priority TEXT NOT NULL,
+    status TEXT NOT NULL,
+    task_count INTEGER NOT NULL,
+    PRIMARY KEY (owner_id, snapshot_date, priority, status)
+);
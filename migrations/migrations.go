@@ -0,0 +1,38 @@
+// Package migrations встраивает SQL-миграции в бинарник, чтобы приложение знало ожидаемую версию схемы
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// FS миграции в формате golang-migrate: NNNNNN_name.up.sql и NNNNNN_name.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion возвращает номер последней миграции — версию, до которой должна быть обновлена схема
+func LatestVersion() (uint, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, name := range files {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("некорректное имя миграции: %s", name)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("некорректный номер миграции %s: %w", name, err)
+		}
+		latest = max(latest, uint(version))
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestLatestVersion(t *testing.T) {
+	ups, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, ups)
+
+	version, err := LatestVersion()
+	require.NoError(t, err)
+	assert.Equal(t, uint(len(ups)), version, "номера миграций идут подряд с 1")
+
+	for _, up := range ups {
+		_, err = fs.Stat(FS, strings.TrimSuffix(up, ".up.sql")+".down.sql")
+		assert.NoError(t, err, "у миграции %s нет down", up)
+	}
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"golang.org/x/crypto/bcrypt"
+)
 
 // HashPassword хеширует пароль
 func HashPassword(password string) (string, error) {
@@ -12,3 +16,12 @@ func HashPassword(password string) (string, error) {
 func CheckPasswordHash(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
+
+// NewTokenID генерирует случайный идентификатор для токенов и сессий
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -7,26 +7,78 @@ import (
 	"time"
 )
 
+const (
+	TokenTypeAccess  = "access"  // Тип access токена
+	TokenTypeRefresh = "refresh" // Тип refresh токена
+)
+
+var (
+	ErrInvalidToken   = errors.New("невалидный токен")
+	ErrTokenExpired   = errors.New("срок действия токена истёк")
+	ErrWrongTokenType = errors.New("неверный тип токена")
+)
+
 // AccessTokenClaim предоставляет payload для access токена
 type AccessTokenClaim struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid"` // Сессия (семейство refresh токенов), в которой выпущен токен
+	TokenType string `json:"token_type"`
+	IssuedMs  int64  `json:"iat_ms"` // Время выпуска в миллисекундах: iat хранит только секунды
 	jwt.RegisteredClaims
 }
 
+// IssuedAtTime время выпуска токена с точностью до миллисекунд
+func (c *AccessTokenClaim) IssuedAtTime() time.Time {
+	return issuedAtTime(c.IssuedMs, c.IssuedAt)
+}
+
 // RefreshTokenClaim предоставляет payload для refresh токена
 type RefreshTokenClaim struct {
-	Email string `json:"email"`
+	UserID    int64  `json:"user_id"`
+	Email     string `json:"email"`
+	FamilyID  string `json:"fid"` // Семейство токенов, порождённых одним входом
+	TokenType string `json:"token_type"`
+	IssuedMs  int64  `json:"iat_ms"` // Время выпуска в миллисекундах: iat хранит только секунды
 	jwt.RegisteredClaims
 }
 
-// GenerateAccessToken генерирует access токен
-func GenerateAccessToken(username, email, secretKey string, ttl time.Duration) (string, error) {
+// IssuedAtTime время выпуска токена с точностью до миллисекунд
+func (c *RefreshTokenClaim) IssuedAtTime() time.Time {
+	return issuedAtTime(c.IssuedMs, c.IssuedAt)
+}
+
+// issuedAtTime берёт время выпуска из iat_ms, а для токенов, выпущенных без него, — из iat
+func issuedAtTime(issuedMs int64, issuedAt *jwt.NumericDate) time.Time {
+	if issuedMs > 0 {
+		return time.UnixMilli(issuedMs)
+	}
+	if issuedAt != nil {
+		return issuedAt.Time
+	}
+	return time.Time{}
+}
+
+// GenerateAccessToken генерирует access токен с уникальным jti
+func GenerateAccessToken(userID int64, username, email, sessionID, secretKey string, ttl time.Duration) (string, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := AccessTokenClaim{
-		Username: username,
-		Email:    email,
+		UserID:    userID,
+		Username:  username,
+		Email:     email,
+		SessionID: sessionID,
+		TokenType: TokenTypeAccess,
+		IssuedMs:  now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -35,11 +87,18 @@ func GenerateAccessToken(username, email, secretKey string, ttl time.Duration) (
 }
 
 // GenerateRefreshToken генерирует refresh токен
-func GenerateRefreshToken(email, secretKey string, ttl time.Duration) (string, error) {
+func GenerateRefreshToken(userID int64, email, familyID, tokenID, secretKey string, ttl time.Duration) (string, error) {
+	now := time.Now()
 	claims := RefreshTokenClaim{
-		Email: email,
+		UserID:    userID,
+		Email:     email,
+		FamilyID:  familyID,
+		TokenType: TokenTypeRefresh,
+		IssuedMs:  now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -49,14 +108,47 @@ func GenerateRefreshToken(email, secretKey string, ttl time.Duration) (string, e
 
 // ValidateToken валидирует токен и возвращает распарсенный jwt.Token
 func ValidateToken(encodedToken string, secretKey []byte) (*jwt.Token, error) {
-	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
+	return parseToken(encodedToken, secretKey, jwt.MapClaims{})
+}
+
+// ParseAccessToken валидирует access токен и возвращает его payload
+func ParseAccessToken(encodedToken string, secretKey []byte) (*AccessTokenClaim, error) {
+	claims := &AccessTokenClaim{}
+	if _, err := parseToken(encodedToken, secretKey, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeAccess {
+		return nil, ErrWrongTokenType
+	}
+
+	return claims, nil
+}
+
+// ParseRefreshToken валидирует refresh токен и возвращает его payload
+func ParseRefreshToken(encodedToken string, secretKey []byte) (*RefreshTokenClaim, error) {
+	claims := &RefreshTokenClaim{}
+	if _, err := parseToken(encodedToken, secretKey, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, ErrWrongTokenType
+	}
+
+	return claims, nil
+}
+
+func parseToken(encodedToken string, secretKey []byte, claims jwt.Claims) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(encodedToken, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("неизвестный метод подписания")
 		}
 		return secretKey, nil
 	})
 	if err != nil {
-		return nil, errors.New("невалидный токен")
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
 	}
 
 	return token, nil